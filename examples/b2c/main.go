@@ -0,0 +1,48 @@
+package main
+
+import (
+	"log"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/b2c"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/client"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/config"
+)
+
+func main() {
+	// Initialize config
+	cfg, err := config.NewConfig(
+		"your-consumer-key",
+		"your-consumer-secret",
+		config.Sandbox,
+		config.WithCertificatePath("path/to/sandbox-certificate.cer"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Create client
+	client := client.NewClient(cfg)
+
+	// Initialize B2C service
+	b2cService := b2c.NewB2CService(client)
+
+	// Pay out to a customer
+	payReq := &b2c.PaymentRequest{
+		InitiatorName:     "testapi",
+		InitiatorPassword: "your-initiator-password",
+		CommandID:         b2c.BusinessPayment,
+		Amount:            "10",
+		PartyA:            "101010",
+		PartyB:            "251700404789",
+		Remarks:           "Refund",
+		QueueTimeOutURL:   "http://mydomain.com/b2c/timeout",
+		ResultURL:         "http://mydomain.com/b2c/result",
+		Occasion:          "Refund",
+	}
+
+	payResp, err := b2cService.ProcessPayment(payReq)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("B2C Payment Response: %+v\n", payResp)
+}
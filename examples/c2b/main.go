@@ -16,6 +16,7 @@ func main() {
 		"your-consumer-key",
 		"your-consumer-secret",
 		config.Sandbox,
+		config.WithCertificatePath("path/to/sandbox-certificate.cer"),
 	)
 	if err != nil {
 		log.Fatal(err)
@@ -59,10 +60,10 @@ func main() {
 			},
 		},
 		Initiator: models.Initiator{
-			IdentifierType:     1,
-			Identifier:         "251799100026",
-			SecurityCredential: "your-security-credential",
-			SecretKey:          "your-secret-key",
+			IdentifierType:    1,
+			Identifier:        "251799100026",
+			InitiatorPassword: "your-initiator-password",
+			SecretKey:         "your-secret-key",
 		},
 		PrimaryParty: models.Party{
 			IdentifierType: 1,
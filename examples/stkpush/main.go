@@ -15,6 +15,7 @@ func main() {
 		"your-consumer-key",
 		"your-consumer-secret",
 		config.Sandbox,
+		config.WithPasskey("554433", "your-passkey"),
 	)
 	if err != nil {
 		log.Fatal(err)
@@ -26,10 +27,9 @@ func main() {
 	// Initialize STK Push service
 	stkService := stkpush.NewSTKPushService(client)
 
-	// Create STK Push request
+	// Create STK Push request; the password is derived from the configured passkey
 	request := &stkpush.STKPushRequest{
 		BusinessShortCode: "554433",
-		Password:          "123",
 		Amount:            "10.00",
 		PartyA:            "251700404789",
 		PartyB:            "554433",
@@ -0,0 +1,27 @@
+// Package transport lets services accept any client with a DoRequest method
+// while still passing their context to clients that support one.
+package transport
+
+import "context"
+
+// Doer is the method every client handed to a service must provide
+type Doer interface {
+	DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+}
+
+// ContextDoer is implemented by clients that honour a context
+type ContextDoer interface {
+	DoRequestContext(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error)
+}
+
+// Do calls DoRequestContext when client implements it, so that ctx reaches
+// the HTTP call, and falls back to DoRequest otherwise
+func Do(ctx context.Context, client Doer, method, endpoint string, body interface{}) ([]byte, error) {
+	if c, ok := client.(ContextDoer); ok {
+		return c.DoRequestContext(ctx, method, endpoint, body)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return client.DoRequest(method, endpoint, body)
+}
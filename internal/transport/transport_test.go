@@ -0,0 +1,53 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type plainClient struct {
+	calls int
+}
+
+func (c *plainClient) DoRequest(method, endpoint string, body interface{}) ([]byte, error) {
+	c.calls++
+	return []byte("plain"), nil
+}
+
+type contextClient struct {
+	plainClient
+	ctx context.Context
+}
+
+func (c *contextClient) DoRequestContext(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+	c.ctx = ctx
+	return []byte("context"), nil
+}
+
+type key struct{}
+
+func TestDo(t *testing.T) {
+	ctx := context.WithValue(context.Background(), key{}, "value")
+
+	cc := &contextClient{}
+	resp, err := Do(ctx, cc, "POST", "/test", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "context", string(resp))
+	assert.Equal(t, "value", cc.ctx.Value(key{}))
+	assert.Equal(t, 0, cc.calls)
+
+	pc := &plainClient{}
+	resp, err = Do(ctx, pc, "POST", "/test", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "plain", string(resp))
+	assert.Equal(t, 1, pc.calls)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Do(cancelled, pc, "POST", "/test", nil)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 1, pc.calls)
+}
@@ -0,0 +1,137 @@
+// Package otelmpesa adapts OpenTelemetry tracers and meters to the telemetry
+// hooks of the M-PESA client. It is a separate module so that the SDK itself
+// does not depend on OpenTelemetry.
+//
+//	c := client.NewClient(cfg,
+//		client.WithTracer(otelmpesa.NewTracer(otel.Tracer("mpesa"))),
+//		client.WithMeter(otelmpesa.NewMeter(otel.Meter("mpesa"))))
+package otelmpesa
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/client"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer is a telemetry.Tracer that starts OpenTelemetry client spans
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer creates a Tracer that starts its spans with tracer
+func NewTracer(tracer trace.Tracer) *Tracer {
+	return &Tracer{tracer: tracer}
+}
+
+// Start implements telemetry.Tracer
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...telemetry.Attribute) (context.Context, telemetry.Span) {
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(convert(attrs)...))
+	return ctx, &otelSpan{span: span}
+}
+
+// otelSpan is the telemetry.Span handed out by Tracer.Start
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s *otelSpan) SetAttributes(attrs ...telemetry.Attribute) {
+	s.span.SetAttributes(convert(attrs)...)
+}
+
+func (s *otelSpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *otelSpan) End() {
+	s.span.End()
+}
+
+// instruments describes the metrics recorded by the client
+var instruments = map[string]struct {
+	description string
+	unit        string
+}{
+	client.MetricRequests:        {"M-PESA API calls by operation and final status code", "{request}"},
+	client.MetricRequestDuration: {"Latency of M-PESA API calls, including retries", "s"},
+	client.MetricRetries:         {"Retried M-PESA API attempts", "{retry}"},
+	client.MetricErrors:          {"Failed M-PESA API calls by error code", "{error}"},
+}
+
+// Meter is a telemetry.Meter that records to OpenTelemetry instruments,
+// creating each instrument the first time it is used
+type Meter struct {
+	meter      metric.Meter
+	mu         sync.Mutex
+	counters   map[string]metric.Int64Counter
+	histograms map[string]metric.Float64Histogram
+}
+
+// NewMeter creates a Meter that creates its instruments with meter
+func NewMeter(meter metric.Meter) *Meter {
+	return &Meter{
+		meter:      meter,
+		counters:   make(map[string]metric.Int64Counter),
+		histograms: make(map[string]metric.Float64Histogram),
+	}
+}
+
+// Add implements telemetry.Meter
+func (m *Meter) Add(ctx context.Context, name string, value int64, attrs ...telemetry.Attribute) {
+	m.mu.Lock()
+	counter, ok := m.counters[name]
+	if !ok {
+		info := instruments[name]
+		// On error OpenTelemetry still returns a usable no-op instrument
+		counter, _ = m.meter.Int64Counter(name, metric.WithDescription(info.description), metric.WithUnit(info.unit))
+		m.counters[name] = counter
+	}
+	m.mu.Unlock()
+
+	counter.Add(ctx, value, metric.WithAttributes(convert(attrs)...))
+}
+
+// Record implements telemetry.Meter
+func (m *Meter) Record(ctx context.Context, name string, value float64, attrs ...telemetry.Attribute) {
+	m.mu.Lock()
+	histogram, ok := m.histograms[name]
+	if !ok {
+		info := instruments[name]
+		histogram, _ = m.meter.Float64Histogram(name, metric.WithDescription(info.description), metric.WithUnit(info.unit))
+		m.histograms[name] = histogram
+	}
+	m.mu.Unlock()
+
+	histogram.Record(ctx, value, metric.WithAttributes(convert(attrs)...))
+}
+
+// convert maps telemetry attributes to OpenTelemetry ones
+func convert(attrs []telemetry.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		switch v := attr.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(attr.Key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(attr.Key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(attr.Key, v))
+		case float64:
+			kvs = append(kvs, attribute.Float64(attr.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(attr.Key, v))
+		default:
+			kvs = append(kvs, attribute.String(attr.Key, fmt.Sprint(v)))
+		}
+	}
+	return kvs
+}
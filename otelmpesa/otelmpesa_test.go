@@ -0,0 +1,132 @@
+package otelmpesa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/client"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/config"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/stkpush"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*client.Client, *tracetest.InMemoryExporter, *sdkmetric.ManualReader) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/token/generate", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"test_token","token_type":"Bearer","expires_in":"3599"}`))
+	})
+	mux.HandleFunc("/", handler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	cfg := &config.Config{
+		ConsumerKey:    "key",
+		ConsumerSecret: "secret",
+		BaseURL:        server.URL,
+		Timeout:        time.Second * 5,
+		RetryCount:     2,
+		RetryWaitTime:  time.Millisecond * 10,
+	}
+	c := client.NewClient(cfg,
+		client.WithTracer(NewTracer(tracerProvider.Tracer("mpesa"))),
+		client.WithMeter(NewMeter(meterProvider.Meter("mpesa"))))
+	return c, exporter, reader
+}
+
+// sum returns the value of the counter name for the data point with attrs
+func sum(t *testing.T, reader *sdkmetric.ManualReader, name string, attrs ...attribute.KeyValue) int64 {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				if dp.Attributes.Equals(ptr(attribute.NewSet(attrs...))) {
+					return dp.Value
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func ptr(set attribute.Set) *attribute.Set {
+	return &set
+}
+
+func TestTracerAndMeter(t *testing.T) {
+	attempts := 0
+	c, exporter, reader := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"MerchantRequestID":"1","CheckoutRequestID":"ws_CO_1","ResponseCode":"0"}`))
+	})
+
+	service := stkpush.NewSTKPushService(c)
+	_, err := service.InitiateSTKPush(&stkpush.STKPushRequest{BusinessShortCode: "554433", Password: "123"})
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	token, call := spans[0], spans[1]
+	assert.Equal(t, "token.generate", token.Name)
+	assert.Equal(t, call.SpanContext.SpanID(), token.Parent.SpanID())
+
+	assert.Equal(t, "stkpush.initiate", call.Name)
+	assert.Equal(t, trace.SpanKindClient, call.SpanKind)
+	assert.Contains(t, call.Attributes, attribute.String(client.AttrEndpoint, "/mpesa/stkpush/v3/processrequest"))
+	assert.Contains(t, call.Attributes, attribute.Int(client.AttrAttempt, 2))
+	assert.Contains(t, call.Attributes, attribute.String(client.AttrResultCode, "0"))
+	assert.Equal(t, codes.Unset, call.Status.Code)
+
+	operation := attribute.String(client.AttrOperation, "stkpush.initiate")
+	assert.Equal(t, int64(1), sum(t, reader, client.MetricRetries, operation))
+	assert.Equal(t, int64(1), sum(t, reader, client.MetricRequests, operation, attribute.Int(client.AttrStatusCode, http.StatusOK)))
+}
+
+func TestTracerRecordsErrors(t *testing.T) {
+	c, exporter, reader := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"requestId":"1","errorCode":"400.002.02","errorMessage":"Bad Request"}`))
+	})
+
+	_, err := c.DoRequest(http.MethodPost, "/mpesa/b2c/v2/paymentrequest", nil)
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	call := spans[1]
+	assert.Equal(t, codes.Error, call.Status.Code)
+	assert.Len(t, call.Events, 1)
+	assert.Contains(t, call.Attributes, attribute.String(client.AttrErrorCode, "400.002.02"))
+
+	assert.Equal(t, int64(1), sum(t, reader, client.MetricErrors,
+		attribute.String(client.AttrOperation, "/mpesa/b2c/v2/paymentrequest"),
+		attribute.String(client.AttrErrorCode, "400.002.02")))
+}
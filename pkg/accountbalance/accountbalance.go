@@ -0,0 +1,180 @@
+package accountbalance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/internal/transport"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/security"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
+)
+
+// Account names reported in the AccountBalance result parameter
+const (
+	WorkingAccount                = "Working Account"
+	FloatAccount                  = "Float Account"
+	UtilityAccount                = "Utility Account"
+	ChargesPaidAccount            = "Charges Paid Account"
+	OrganizationSettlementAccount = "Organization Settlement Account"
+)
+
+type AccountBalanceService struct {
+	client interface {
+		DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+	}
+}
+
+func NewAccountBalanceService(client interface {
+	DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+}) *AccountBalanceService {
+	return &AccountBalanceService{
+		client: client,
+	}
+}
+
+// BalanceRequest represents an account balance query
+type BalanceRequest struct {
+	Initiator          string `json:"Initiator"`
+	SecurityCredential string `json:"SecurityCredential"`
+	CommandID          string `json:"CommandID"`
+	PartyA             string `json:"PartyA"`
+	IdentifierType     string `json:"IdentifierType"`
+	Remarks            string `json:"Remarks"`
+	QueueTimeOutURL    string `json:"QueueTimeOutURL"`
+	ResultURL          string `json:"ResultURL"`
+
+	// InitiatorPassword is encrypted into SecurityCredential when the latter is empty
+	InitiatorPassword string `json:"-"`
+}
+
+// BalanceResponse represents the synchronous acknowledgement of a balance query
+type BalanceResponse struct {
+	ConversationID           string `json:"ConversationID"`
+	OriginatorConversationID string `json:"OriginatorConversationID"`
+	ResponseCode             string `json:"ResponseCode"`
+	ResponseDescription      string `json:"ResponseDescription"`
+}
+
+// Account represents the balance of a single account of a shortcode
+type Account struct {
+	Name             string
+	Currency         string
+	CurrentBalance   float64
+	AvailableBalance float64
+	ReservedBalance  float64
+	UnclearedBalance float64
+}
+
+// BalanceResult represents the asynchronous result of a balance query
+type BalanceResult struct {
+	models.Result
+
+	Accounts        []Account
+	BOCompletedTime string
+}
+
+// Account returns the account with the given name, such as WorkingAccount
+func (r *BalanceResult) Account(name string) (Account, bool) {
+	for _, account := range r.Accounts {
+		if account.Name == name {
+			return account, true
+		}
+	}
+	return Account{}, false
+}
+
+// QueryBalance requests the balances of a shortcode
+func (s *AccountBalanceService) QueryBalance(req *BalanceRequest) (*BalanceResponse, error) {
+	return s.QueryBalanceContext(context.Background(), req)
+}
+
+// QueryBalanceContext is like QueryBalance but uses ctx for cancellation and deadlines
+func (s *AccountBalanceService) QueryBalanceContext(ctx context.Context, req *BalanceRequest) (*BalanceResponse, error) {
+	if req.CommandID == "" {
+		req.CommandID = "AccountBalance"
+	}
+
+	credential, err := security.ResolveCredential(s.client, req.SecurityCredential, req.InitiatorPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate security credential: %w", err)
+	}
+	req.SecurityCredential = credential
+
+	endpoint := "/mpesa/accountbalance/v1/query"
+	resp, err := transport.Do(telemetry.WithOperation(ctx, "accountbalance.query"), s.client, "POST", endpoint, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account balance: %w", err)
+	}
+
+	var balanceResp BalanceResponse
+	if err := json.Unmarshal(resp, &balanceResp); err != nil {
+		return nil, fmt.Errorf("failed to parse account balance response: %w", err)
+	}
+
+	return &balanceResp, nil
+}
+
+// ParseResult decodes the result M-PESA posts to the ResultURL of a balance query
+func ParseResult(r io.Reader) (*BalanceResult, error) {
+	result, err := models.ParseResult(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse account balance result: %w", err)
+	}
+
+	balanceResult := &BalanceResult{Result: *result}
+	balanceResult.BOCompletedTime, _ = result.ResultParameters.Get("BOCompletedTime")
+	if v, ok := result.ResultParameters.Get("AccountBalance"); ok {
+		if balanceResult.Accounts, err = ParseAccountBalance(v); err != nil {
+			return nil, fmt.Errorf("failed to parse account balance result: %w", err)
+		}
+	}
+
+	return balanceResult, nil
+}
+
+// ParseAccountBalance splits an AccountBalance string of the form
+// "Name|Currency|Current|Available|Reserved|Uncleared&..." into accounts
+func ParseAccountBalance(s string) ([]Account, error) {
+	var accounts []Account
+	for _, entry := range strings.Split(s, "&") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		fields := strings.Split(entry, "|")
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("invalid account balance entry: %q", entry)
+		}
+
+		account := Account{
+			Name:     fields[0],
+			Currency: fields[1],
+		}
+		amounts := []*float64{
+			&account.CurrentBalance,
+			&account.AvailableBalance,
+			&account.ReservedBalance,
+			&account.UnclearedBalance,
+		}
+		for i, field := range fields[2:] {
+			if i >= len(amounts) {
+				break
+			}
+			amount, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid amount in account balance entry %q: %w", entry, err)
+			}
+			*amounts[i] = amount
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
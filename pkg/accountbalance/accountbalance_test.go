@@ -0,0 +1,86 @@
+package accountbalance
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct {
+	doRequestFunc func(method, endpoint string, body interface{}) ([]byte, error)
+}
+
+func (m *mockClient) DoRequest(method, endpoint string, body interface{}) ([]byte, error) {
+	return m.doRequestFunc(method, endpoint, body)
+}
+
+func TestQueryBalance(t *testing.T) {
+	mockClient := &mockClient{
+		doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+			assert.Equal(t, "/mpesa/accountbalance/v1/query", endpoint)
+			assert.Equal(t, "AccountBalance", body.(*BalanceRequest).CommandID)
+			return json.RawMessage(`{
+				"ConversationID": "AG_20240101_1234",
+				"OriginatorConversationID": "5678",
+				"ResponseCode": "0",
+				"ResponseDescription": "Accept the service request successfully."
+			}`), nil
+		},
+	}
+	service := NewAccountBalanceService(mockClient)
+
+	response, err := service.QueryBalance(&BalanceRequest{PartyA: "101010", IdentifierType: "4"})
+	assert.NoError(t, err)
+	assert.Equal(t, "AG_20240101_1234", response.ConversationID)
+	assert.Equal(t, "0", response.ResponseCode)
+}
+
+func TestParseResult(t *testing.T) {
+	payload := `{
+		"Result": {
+			"ResultType": 0,
+			"ResultCode": 0,
+			"ResultDesc": "The service request is processed successfully.",
+			"OriginatorConversationID": "5678",
+			"ConversationID": "AG_20240101_1234",
+			"TransactionID": "OA90000000",
+			"ResultParameters": {
+				"ResultParameter": [
+					{"Key": "AccountBalance", "Value": "Working Account|ETB|46713.00|46713.00|0.00|0.00&Utility Account|ETB|49217.00|49217.00|0.00|0.00&Charges Paid Account|ETB|-220.00|-220.00|0.00|0.00"},
+					{"Key": "BOCompletedTime", "Value": 20240101125710}
+				]
+			}
+		}
+	}`
+
+	result, err := ParseResult(strings.NewReader(payload))
+	assert.NoError(t, err)
+	assert.Len(t, result.Accounts, 3)
+	assert.Equal(t, "20240101125710", result.BOCompletedTime)
+
+	working, ok := result.Account(WorkingAccount)
+	assert.True(t, ok)
+	assert.Equal(t, Account{
+		Name:             "Working Account",
+		Currency:         "ETB",
+		CurrentBalance:   46713,
+		AvailableBalance: 46713,
+	}, working)
+
+	charges, ok := result.Account(ChargesPaidAccount)
+	assert.True(t, ok)
+	assert.Equal(t, -220.0, charges.AvailableBalance)
+
+	_, ok = result.Account(FloatAccount)
+	assert.False(t, ok)
+}
+
+func TestParseAccountBalanceInvalid(t *testing.T) {
+	_, err := ParseAccountBalance("Working Account|ETB|abc")
+	assert.Error(t, err)
+
+	_, err = ParseAccountBalance("Working Account")
+	assert.Error(t, err)
+}
@@ -1,67 +1,118 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"sync"
 	"time"
 
+	"github.com/natnael-alemayehu/mpesa-sdk-go/internal/transport"
 	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
 )
 
 type AuthService struct {
 	client interface {
 		DoRequest(method, endpoint string, body interface{}) ([]byte, error)
 	}
-	mu       sync.RWMutex
-	token    string
-	tokenExp time.Time
+	tokens      TokenSource
+	refreshSkew time.Duration
+	onExpired   func(expired *Token)
+	// tokenOptions records that an option configuring the token source was given
+	tokenOptions bool
 }
 
+// AuthOption defines a function type for auth service options
+type AuthOption func(*AuthService)
+
+// NewAuthService creates an auth service for client. When client exposes its
+// own TokenSource, as client.Client does, the service shares it so that the
+// client and the service use the same cached token and the same fetch path.
+// That source is configured through the client, with
+// config.WithTokenRefreshSkew and config.WithTokenExpiredHook, so passing
+// WithRefreshSkew or WithTokenExpiredHook together with such a client panics.
+// Other clients get a token source of their own that fetches through DoRequest.
 func NewAuthService(client interface {
 	DoRequest(method, endpoint string, body interface{}) ([]byte, error)
-}) *AuthService {
-	return &AuthService{
+}, options ...AuthOption) *AuthService {
+	s := &AuthService{
 		client: client,
+		// Refresh a minute before expiry for safety
+		refreshSkew: time.Minute,
+	}
+
+	// Apply options
+	for _, option := range options {
+		option(s)
+	}
+
+	if p, ok := client.(interface{ TokenSource() TokenSource }); ok {
+		if s.tokenOptions {
+			panic("auth: WithRefreshSkew and WithTokenExpiredHook do not apply to a client with its own token source; use config.WithTokenRefreshSkew and config.WithTokenExpiredHook")
+		}
+		s.tokens = p.TokenSource()
+		return s
+	}
+
+	s.tokens = NewCachedTokenSource(s.refreshToken, s.refreshSkew, WithExpiredHook(s.onExpired))
+	return s
+}
+
+// WithRefreshSkew sets how long before expiry a token is refreshed; it only
+// applies to clients without their own token source
+func WithRefreshSkew(skew time.Duration) AuthOption {
+	return func(s *AuthService) {
+		s.refreshSkew = skew
+		s.tokenOptions = true
+	}
+}
+
+// WithTokenExpiredHook sets a function called with each token that is about to
+// be replaced; it only applies to clients without their own token source
+func WithTokenExpiredHook(hook func(expired *Token)) AuthOption {
+	return func(s *AuthService) {
+		s.onExpired = hook
+		s.tokenOptions = true
 	}
 }
 
 func (s *AuthService) GetToken() (string, error) {
-	s.mu.RLock()
+	return s.GetTokenContext(context.Background())
+}
 
-	if s.token != "" && time.Now().Before(s.tokenExp) {
-		fmt.Printf("The token is still valid: %s\n", s.token)
-		defer s.mu.RUnlock()
-		return s.token, nil
+// GetTokenContext is like GetToken but aborts a token refresh when ctx is done
+func (s *AuthService) GetTokenContext(ctx context.Context) (string, error) {
+	token, err := s.tokens.Token(ctx)
+	if err != nil {
+		return "", err
 	}
-	s.mu.RUnlock()
-	return s.refreshToken()
+	return token.AccessToken, nil
 }
 
-func (s *AuthService) refreshToken() (string, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// Token implements TokenSource
+func (s *AuthService) Token(ctx context.Context) (*Token, error) {
+	return s.tokens.Token(ctx)
+}
 
-	// Double-check token expiration after acquiring lock
-	if s.token != "" && time.Now().Before(s.tokenExp) {
-		return s.token, nil
+// Current returns the cached token without fetching, or nil if there is none
+func (s *AuthService) Current() *Token {
+	if tokens, ok := s.tokens.(interface{ Current() *Token }); ok {
+		return tokens.Current()
 	}
+	return nil
+}
 
+func (s *AuthService) refreshToken(ctx context.Context) (*Token, error) {
 	endpoint := "/v1/token/generate?grant_type=client_credentials"
-	resp, err := s.client.DoRequest("GET", endpoint, nil)
+	resp, err := transport.Do(telemetry.WithOperation(ctx, "token.generate"), s.client, "GET", endpoint, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to refresh token: %w", err)
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 
 	var authResp models.AuthResponse
 	if err := json.Unmarshal(resp, &authResp); err != nil {
-		return "", fmt.Errorf("failed to parse auth response: %w", err)
+		return nil, fmt.Errorf("failed to parse auth response: %w", err)
 	}
 
-	s.token = authResp.AccessToken
-	// Convert expires_in to duration and subtract a minute for safety
-	expiresIn := 3600 * time.Second // Default to 1 hour if parsing fails
-	s.tokenExp = time.Now().Add(expiresIn - time.Minute)
-
-	return s.token, nil
+	return NewToken(&authResp)
 }
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 	"time"
@@ -28,9 +29,33 @@ func TestGetToken(t *testing.T) {
 	assert.Equal(t, "mock_token", token)
 
 	// Simulate token expiration
-	authService.tokenExp = time.Now().Add(-time.Minute)
+	authService.tokens.(*CachedTokenSource).token.Expiry = time.Now().Add(-time.Minute)
 
 	token, err = authService.GetToken()
 	assert.NoError(t, err)
 	assert.Equal(t, "mock_token", token)
 }
+
+// sharedMockClient is a MockClient that exposes its own token source
+type sharedMockClient struct {
+	MockClient
+	tokens TokenSource
+}
+
+func (m *sharedMockClient) TokenSource() TokenSource {
+	return m.tokens
+}
+
+func TestAuthServiceSharedTokenSource(t *testing.T) {
+	client := &sharedMockClient{tokens: NewCachedTokenSource(func(ctx context.Context) (*Token, error) {
+		return &Token{AccessToken: "client_token", Expiry: time.Now().Add(time.Hour)}, nil
+	}, time.Minute)}
+
+	token, err := NewAuthService(client).GetToken()
+	assert.NoError(t, err)
+	assert.Equal(t, "client_token", token)
+
+	// Token source options would be silently ignored, so they are refused
+	assert.Panics(t, func() { NewAuthService(client, WithRefreshSkew(time.Second)) })
+	assert.Panics(t, func() { NewAuthService(client, WithTokenExpiredHook(func(*Token) {})) })
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileTokenStore is a TokenStore that keeps tokens as files in a directory,
+// sharing them between processes on the same host or a shared volume
+type FileTokenStore struct {
+	dir string
+}
+
+// NewFileTokenStore creates a token store in dir, creating it if needed
+func NewFileTokenStore(dir string) (*FileTokenStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create token directory: %w", err)
+	}
+	return &FileTokenStore{dir: dir}, nil
+}
+
+// path returns the file used for key, hashed so any key is a valid file name
+func (f *FileTokenStore) path(key, ext string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:16])+ext)
+}
+
+// Load implements TokenStore
+func (f *FileTokenStore) Load(ctx context.Context, key string) (*Token, error) {
+	data, err := os.ReadFile(f.path(key, ".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse token file: %w", err)
+	}
+	return &token, nil
+}
+
+// Save implements TokenStore
+func (f *FileTokenStore) Save(ctx context.Context, key string, token *Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename it so readers never see a partial token
+	tmp, err := os.CreateTemp(f.dir, "token-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(key, ".json"))
+}
+
+// Delete implements TokenStore
+func (f *FileTokenStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(f.path(key, ".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Lock implements TokenStore by exclusively creating a lock file holding
+// owner; lock files older than ttl are considered abandoned and taken over
+func (f *FileTokenStore) Lock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	path := f.path(key, ".lock")
+
+	for i := 0; i < 2; i++ {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			if _, err := file.WriteString(owner); err != nil {
+				file.Close()
+				return false, err
+			}
+			return true, file.Close()
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return false, err
+		}
+
+		// Read the holder before checking the age so that a lock replaced in
+		// between is not mistaken for the stale one
+		holder, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if time.Since(info.ModTime()) < ttl {
+			return false, nil
+		}
+		if _, err := f.release(path, string(holder)); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// Unlock implements TokenStore
+func (f *FileTokenStore) Unlock(ctx context.Context, key, owner string) error {
+	_, err := f.release(f.path(key, ".lock"), owner)
+	return err
+}
+
+// release removes the lock file at path if it holds owner. The file is first
+// renamed aside, which only one process can do, and checked there; a lock
+// that turns out to belong to someone else is linked back into place unless
+// a new lock has been taken in the meantime.
+func (f *FileTokenStore) release(path, owner string) (bool, error) {
+	suffix, err := newLockOwner()
+	if err != nil {
+		return false, err
+	}
+	aside := path + "." + suffix
+
+	if err := os.Rename(path, aside); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer os.Remove(aside)
+
+	holder, err := os.ReadFile(aside)
+	if err != nil {
+		return false, err
+	}
+	if string(holder) == owner {
+		return true, nil
+	}
+
+	if err := os.Link(aside, path); err != nil && !errors.Is(err, fs.ErrExist) {
+		return false, err
+	}
+	return false, nil
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// KeyValueStore is the subset of a shared cache such as Redis needed to
+// share tokens. SetNX must only set the value when the key does not exist,
+// like Redis SET with the NX option. CompareAndDelete must check and delete
+// atomically; with Redis that takes a script such as
+//
+//	if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0
+type KeyValueStore interface {
+	// Get returns the value stored under key and whether it exists
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Delete(ctx context.Context, key string) error
+	// CompareAndDelete deletes key only if it holds value, reporting whether it did
+	CompareAndDelete(ctx context.Context, key string, value []byte) (bool, error)
+}
+
+// KVTokenStore adapts a KeyValueStore to a TokenStore
+type KVTokenStore struct {
+	kv KeyValueStore
+}
+
+// NewKVTokenStore creates a token store backed by kv
+func NewKVTokenStore(kv KeyValueStore) *KVTokenStore {
+	return &KVTokenStore{kv: kv}
+}
+
+// Load implements TokenStore
+func (s *KVTokenStore) Load(ctx context.Context, key string) (*Token, error) {
+	data, ok, err := s.kv.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse stored token: %w", err)
+	}
+	return &token, nil
+}
+
+// Save implements TokenStore; the entry expires together with the token
+func (s *KVTokenStore) Save(ctx context.Context, key string, token *Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Until(token.Expiry)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.kv.Set(ctx, key, data, ttl)
+}
+
+// Delete implements TokenStore
+func (s *KVTokenStore) Delete(ctx context.Context, key string) error {
+	return s.kv.Delete(ctx, key)
+}
+
+// Lock implements TokenStore
+func (s *KVTokenStore) Lock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	return s.kv.SetNX(ctx, key+":lock", []byte(owner), ttl)
+}
+
+// Unlock implements TokenStore
+func (s *KVTokenStore) Unlock(ctx context.Context, key, owner string) error {
+	_, err := s.kv.CompareAndDelete(ctx, key+":lock", []byte(owner))
+	return err
+}
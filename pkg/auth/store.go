@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TokenStore persists tokens so that several processes can share them.
+// Lock and Unlock guard refreshes: only the holder of the lock for a key
+// fetches a new token, everyone else waits for it to appear in the store.
+type TokenStore interface {
+	// Load returns the stored token, or nil if there is none
+	Load(ctx context.Context, key string) (*Token, error)
+	Save(ctx context.Context, key string, token *Token) error
+	Delete(ctx context.Context, key string) error
+	// Lock tries to take the refresh lock for key on behalf of owner, a
+	// random value unique to the caller. The lock expires after ttl so that
+	// a crashed holder cannot block refreshes forever.
+	Lock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	// Unlock releases the lock for key only if owner still holds it, so a
+	// holder whose lock expired cannot release a lock taken over by another
+	Unlock(ctx context.Context, key, owner string) error
+}
+
+// SharedTokenSource is a TokenSource backed by a TokenStore
+type SharedTokenSource struct {
+	store        TokenStore
+	key          string
+	fetch        TokenFetcher
+	earlyRefresh time.Duration
+	lockTTL      time.Duration
+	pollInterval time.Duration
+	cache        *CachedTokenSource
+}
+
+// NewSharedTokenSource creates a token source that shares tokens under key in store
+func NewSharedTokenSource(store TokenStore, key string, fetch TokenFetcher, earlyRefresh time.Duration, options ...TokenSourceOption) *SharedTokenSource {
+	s := &SharedTokenSource{
+		store:        store,
+		key:          key,
+		fetch:        fetch,
+		earlyRefresh: earlyRefresh,
+		lockTTL:      time.Second * 30,
+		pollInterval: time.Millisecond * 100,
+	}
+	s.cache = NewCachedTokenSource(s.load, earlyRefresh, options...)
+	return s
+}
+
+// Token implements TokenSource
+func (s *SharedTokenSource) Token(ctx context.Context) (*Token, error) {
+	return s.cache.Token(ctx)
+}
+
+// Current returns the cached token without fetching, or nil if there is none
+func (s *SharedTokenSource) Current() *Token {
+	return s.cache.Current()
+}
+
+// Invalidate discards the cached token and removes it from the store if no
+// other process has replaced it yet; ctx bounds the calls to the store
+func (s *SharedTokenSource) Invalidate(ctx context.Context) error {
+	rejected := s.cache.Current()
+	s.cache.Invalidate()
+
+	if rejected == nil {
+		return nil
+	}
+
+	stored, err := s.store.Load(ctx, s.key)
+	if err != nil {
+		return fmt.Errorf("failed to load token: %w", err)
+	}
+	if stored == nil || stored.AccessToken != rejected.AccessToken {
+		return nil
+	}
+	if err := s.store.Delete(ctx, s.key); err != nil {
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+	return nil
+}
+
+// load returns a fresh token from the store, refreshing it if this process wins the lock
+func (s *SharedTokenSource) load(ctx context.Context) (*Token, error) {
+	for {
+		token, err := s.store.Load(ctx, s.key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load token: %w", err)
+		}
+		if s.fresh(token) {
+			return token, nil
+		}
+
+		owner, err := newLockOwner()
+		if err != nil {
+			return nil, err
+		}
+		locked, err := s.store.Lock(ctx, s.key, owner, s.lockTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock token: %w", err)
+		}
+		if locked {
+			return s.refresh(ctx, owner)
+		}
+
+		// Another process is refreshing; wait for its token
+		timer := time.NewTimer(s.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// refresh fetches and stores a new token while owner holds the lock
+func (s *SharedTokenSource) refresh(ctx context.Context, owner string) (*Token, error) {
+	defer s.store.Unlock(context.WithoutCancel(ctx), s.key, owner)
+
+	// Another process may have refreshed between our load and the lock
+	if token, err := s.store.Load(ctx, s.key); err == nil && s.fresh(token) {
+		return token, nil
+	}
+
+	token, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Save(ctx, s.key, token); err != nil {
+		return nil, fmt.Errorf("failed to save token: %w", err)
+	}
+	return token, nil
+}
+
+func (s *SharedTokenSource) fresh(token *Token) bool {
+	return token.Valid() && time.Until(token.Expiry) > s.earlyRefresh
+}
+
+// newLockOwner returns a random value identifying a single lock acquisition
+func newLockOwner() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock owner: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// MemoryTokenStore is a TokenStore for tokens shared within a single process
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]*Token
+	locks  map[string]memoryLock
+}
+
+// memoryLock is a refresh lock held in a MemoryTokenStore
+type memoryLock struct {
+	owner  string
+	expiry time.Time
+}
+
+// NewMemoryTokenStore creates an empty in-memory token store
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: make(map[string]*Token),
+		locks:  make(map[string]memoryLock),
+	}
+}
+
+// Load implements TokenStore
+func (m *MemoryTokenStore) Load(ctx context.Context, key string) (*Token, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, ok := m.tokens[key]
+	if !ok {
+		return nil, nil
+	}
+	copied := *token
+	return &copied, nil
+}
+
+// Save implements TokenStore
+func (m *MemoryTokenStore) Save(ctx context.Context, key string, token *Token) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *token
+	m.tokens[key] = &copied
+	return nil
+}
+
+// Delete implements TokenStore
+func (m *MemoryTokenStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.tokens, key)
+	return nil
+}
+
+// Lock implements TokenStore
+func (m *MemoryTokenStore) Lock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if lock, ok := m.locks[key]; ok && time.Now().Before(lock.expiry) {
+		return false, nil
+	}
+	m.locks[key] = memoryLock{owner: owner, expiry: time.Now().Add(ttl)}
+	return true, nil
+}
+
+// Unlock implements TokenStore
+func (m *MemoryTokenStore) Unlock(ctx context.Context, key, owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if lock, ok := m.locks[key]; ok && lock.owner == owner {
+		delete(m.locks, key)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapKV is an in-memory KeyValueStore standing in for Redis
+type mapKV struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (m *mapKV) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.values[key]
+	return v, ok, nil
+}
+
+func (m *mapKV) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+func (m *mapKV) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.values[key]; ok {
+		return false, nil
+	}
+	m.values[key] = value
+	return true, nil
+}
+
+func (m *mapKV) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.values, key)
+	return nil
+}
+
+func (m *mapKV) CompareAndDelete(ctx context.Context, key string, value []byte) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.values[key]; !ok || string(v) != string(value) {
+		return false, nil
+	}
+	delete(m.values, key)
+	return true, nil
+}
+
+func newFileStore(t *testing.T) TokenStore {
+	store, err := NewFileTokenStore(t.TempDir())
+	require.NoError(t, err)
+	return store
+}
+
+var tokenStores = map[string]func(t *testing.T) TokenStore{
+	"memory": func(t *testing.T) TokenStore { return NewMemoryTokenStore() },
+	"file":   newFileStore,
+	"kv":     func(t *testing.T) TokenStore { return NewKVTokenStore(&mapKV{values: map[string][]byte{}}) },
+}
+
+func TestSharedTokenSourceSingleRefresh(t *testing.T) {
+	for name, newStore := range tokenStores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+
+			var fetches int32
+			fetch := func(ctx context.Context) (*Token, error) {
+				atomic.AddInt32(&fetches, 1)
+				time.Sleep(time.Millisecond * 50)
+				return &Token{AccessToken: "shared_token", Expiry: time.Now().Add(time.Hour)}, nil
+			}
+
+			// Each source stands in for a separate replica
+			var wg sync.WaitGroup
+			for replica := 0; replica < 5; replica++ {
+				source := NewSharedTokenSource(store, "mpesa:token:test", fetch, time.Minute)
+				source.pollInterval = time.Millisecond * 10
+				for i := 0; i < 10; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						token, err := source.Token(context.Background())
+						assert.NoError(t, err)
+						assert.Equal(t, "shared_token", token.AccessToken)
+					}()
+				}
+			}
+			wg.Wait()
+
+			assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+		})
+	}
+}
+
+func TestTokenStoreLockOwner(t *testing.T) {
+	for name, newStore := range tokenStores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			ctx := context.Background()
+
+			locked, err := store.Lock(ctx, "key", "a", time.Minute)
+			assert.NoError(t, err)
+			assert.True(t, locked)
+
+			// Only the owner can release the lock
+			assert.NoError(t, store.Unlock(ctx, "key", "b"))
+			locked, err = store.Lock(ctx, "key", "b", time.Minute)
+			assert.NoError(t, err)
+			assert.False(t, locked)
+
+			assert.NoError(t, store.Unlock(ctx, "key", "a"))
+			locked, err = store.Lock(ctx, "key", "b", time.Minute)
+			assert.NoError(t, err)
+			assert.True(t, locked)
+		})
+	}
+}
+
+func TestSharedTokenSourceInvalidate(t *testing.T) {
+	store := NewMemoryTokenStore()
+	fetches := 0
+	source := NewSharedTokenSource(store, "key", func(ctx context.Context) (*Token, error) {
+		fetches++
+		return &Token{AccessToken: "token", Expiry: time.Now().Add(time.Hour)}, nil
+	}, time.Minute)
+
+	_, err := source.Token(context.Background())
+	assert.NoError(t, err)
+
+	assert.NoError(t, source.Invalidate(context.Background()))
+	stored, err := store.Load(context.Background(), "key")
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+
+	_, err = source.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fetches)
+}
+
+// stalledStore is a TokenStore whose backend never answers
+type stalledStore struct {
+	*MemoryTokenStore
+}
+
+func (s stalledStore) Load(ctx context.Context, key string) (*Token, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSharedTokenSourceInvalidateStalledStore(t *testing.T) {
+	source := NewSharedTokenSource(stalledStore{NewMemoryTokenStore()}, "key", nil, time.Minute)
+	source.cache.token = &Token{AccessToken: "token", Expiry: time.Now().Add(time.Hour)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+
+	err := source.Invalidate(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, source.Current())
+}
+
+func TestFileTokenStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileTokenStore(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	token, err := store.Load(ctx, "key")
+	assert.NoError(t, err)
+	assert.Nil(t, token)
+
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	assert.NoError(t, store.Save(ctx, "key", &Token{AccessToken: "token", TokenType: "Bearer", Expiry: expiry}))
+
+	token, err = store.Load(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "token", token.AccessToken)
+	assert.True(t, expiry.Equal(token.Expiry))
+
+	locked, err := store.Lock(ctx, "key", "a", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	locked, err = store.Lock(ctx, "key", "b", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, locked)
+
+	// A lock older than its ttl is taken over
+	stale := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(store.path("key", ".lock"), stale, stale))
+	locked, err = store.Lock(ctx, "key", "b", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	// The previous holder cannot release the lock it lost
+	assert.NoError(t, store.Unlock(ctx, "key", "a"))
+	holder, err := os.ReadFile(store.path("key", ".lock"))
+	assert.NoError(t, err)
+	assert.Equal(t, "b", string(holder))
+
+	assert.NoError(t, store.Unlock(ctx, "key", "b"))
+	assert.NoError(t, store.Delete(ctx, "key"))
+
+	entries, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestFileTokenStoreStaleTakeover(t *testing.T) {
+	store, err := NewFileTokenStore(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	locked, err := store.Lock(ctx, "key", "crashed", time.Minute)
+	require.NoError(t, err)
+	require.True(t, locked)
+	stale := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(store.path("key", ".lock"), stale, stale))
+
+	var winners int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(owner string) {
+			defer wg.Done()
+			locked, err := store.Lock(ctx, "key", owner, time.Minute)
+			assert.NoError(t, err)
+			if locked {
+				atomic.AddInt32(&winners, 1)
+			}
+		}(string(rune('a' + i)))
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&winners))
+}
@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+)
+
+// DefaultTokenLifetime is assumed when a token response carries no expires_in
+const DefaultTokenLifetime = time.Hour
+
+// Token represents an OAuth access token
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+// NewToken builds a Token from a token endpoint response
+func NewToken(resp *models.AuthResponse) (*Token, error) {
+	if resp.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+
+	lifetime := resp.ExpiresIn.Duration()
+	if lifetime <= 0 {
+		lifetime = DefaultTokenLifetime
+	}
+
+	return &Token{
+		AccessToken: resp.AccessToken,
+		TokenType:   resp.TokenType,
+		Expiry:      time.Now().Add(lifetime),
+	}, nil
+}
+
+// Valid reports whether the token is set and has not expired
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && time.Now().Before(t.Expiry)
+}
+
+// ExpiresIn returns the remaining lifetime of the token, or 0 once it has expired
+func (t *Token) ExpiresIn() time.Duration {
+	if !t.Valid() {
+		return 0
+	}
+	return time.Until(t.Expiry)
+}
+
+// TokenSource supplies access tokens; implementations must be safe for concurrent use
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenFetcher fetches a new access token from the token endpoint
+type TokenFetcher func(ctx context.Context) (*Token, error)
+
+// CachedTokenSource caches tokens from a TokenFetcher. Concurrent callers that
+// need a new token share a single fetch, and tokens are refreshed early so
+// that they do not expire while a request is in flight.
+type CachedTokenSource struct {
+	fetch        TokenFetcher
+	earlyRefresh time.Duration
+	onExpired    func(expired *Token)
+
+	mu    sync.Mutex
+	token *Token
+	call  *tokenCall
+}
+
+// tokenCall is a token fetch that other callers can wait on
+type tokenCall struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// TokenSourceOption defines a function type for token source options
+type TokenSourceOption func(*CachedTokenSource)
+
+// WithExpiredHook sets a function called with a token that is about to be
+// replaced, either because it reached the refresh window or was rejected
+func WithExpiredHook(hook func(expired *Token)) TokenSourceOption {
+	return func(s *CachedTokenSource) {
+		s.onExpired = hook
+	}
+}
+
+// NewCachedTokenSource creates a token source that refreshes tokens earlyRefresh before they expire
+func NewCachedTokenSource(fetch TokenFetcher, earlyRefresh time.Duration, options ...TokenSourceOption) *CachedTokenSource {
+	s := &CachedTokenSource{
+		fetch:        fetch,
+		earlyRefresh: earlyRefresh,
+	}
+
+	// Apply options
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Current returns the cached token without fetching, or nil if there is none
+func (s *CachedTokenSource) Current() *Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.token
+}
+
+// Token returns the cached token, fetching a new one when it is about to expire
+func (s *CachedTokenSource) Token(ctx context.Context) (*Token, error) {
+	for {
+		s.mu.Lock()
+		current := s.token
+		if current.Valid() && time.Until(current.Expiry) > s.earlyRefresh {
+			s.mu.Unlock()
+			return current, nil
+		}
+
+		// Wait for a fetch started by another caller
+		if call := s.call; call != nil {
+			s.mu.Unlock()
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			// The fetching caller gave up; try again with our own context
+			if isContextError(call.err) && ctx.Err() == nil {
+				continue
+			}
+			return call.token, call.err
+		}
+
+		call := &tokenCall{done: make(chan struct{})}
+		s.call = call
+		s.mu.Unlock()
+
+		if current != nil && s.onExpired != nil {
+			s.onExpired(current)
+		}
+
+		call.token, call.err = s.fetch(ctx)
+
+		s.mu.Lock()
+		if call.err == nil {
+			s.token = call.token
+		} else if current.Valid() {
+			// Keep using the old token until it actually expires
+			call.token, call.err = current, nil
+		}
+		s.call = nil
+		s.mu.Unlock()
+
+		close(call.done)
+		return call.token, call.err
+	}
+}
+
+// Invalidate discards the cached token so the next call fetches a new one
+func (s *CachedTokenSource) Invalidate() {
+	s.mu.Lock()
+	rejected := s.token
+	s.token = nil
+	s.mu.Unlock()
+
+	if rejected != nil && s.onExpired != nil {
+		s.onExpired(rejected)
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
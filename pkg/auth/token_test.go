@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedTokenSourceDeduplicatesFetches(t *testing.T) {
+	var fetches int32
+	source := NewCachedTokenSource(func(ctx context.Context) (*Token, error) {
+		atomic.AddInt32(&fetches, 1)
+		time.Sleep(time.Millisecond * 20)
+		return &Token{AccessToken: "mock_token", Expiry: time.Now().Add(time.Hour)}, nil
+	}, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := source.Token(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "mock_token", token.AccessToken)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+}
+
+func TestCachedTokenSourceEarlyRefresh(t *testing.T) {
+	fetches := 0
+	fail := false
+	source := NewCachedTokenSource(func(ctx context.Context) (*Token, error) {
+		fetches++
+		if fail {
+			return nil, errors.New("token endpoint unavailable")
+		}
+		// Expires within the early refresh window
+		return &Token{AccessToken: "mock_token", Expiry: time.Now().Add(time.Second * 30)}, nil
+	}, time.Minute)
+
+	_, err := source.Token(context.Background())
+	assert.NoError(t, err)
+	_, err = source.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fetches)
+
+	// A failed early refresh falls back to the still valid token
+	fail = true
+	token, err := source.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "mock_token", token.AccessToken)
+
+	source.Invalidate()
+	_, err = source.Token(context.Background())
+	assert.EqualError(t, err, "token endpoint unavailable")
+}
+
+func TestCachedTokenSourceCancelledFetch(t *testing.T) {
+	started := make(chan struct{})
+	var fetches int32
+	source := NewCachedTokenSource(func(ctx context.Context) (*Token, error) {
+		if atomic.AddInt32(&fetches, 1) == 1 {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return &Token{AccessToken: "mock_token", Expiry: time.Now().Add(time.Hour)}, nil
+	}, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		_, err := source.Token(ctx)
+		leader <- err
+	}()
+	<-started
+
+	waiter := make(chan *Token, 1)
+	go func() {
+		token, _ := source.Token(context.Background())
+		waiter <- token
+	}()
+
+	time.Sleep(time.Millisecond * 10)
+	cancel()
+
+	assert.True(t, errors.Is(<-leader, context.Canceled))
+	assert.Equal(t, "mock_token", (<-waiter).AccessToken)
+}
+
+func TestNewToken(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		expectedLifetime time.Duration
+		expectedError    bool
+	}{
+		{"string expires_in", `{"access_token":"t","expires_in":"3599"}`, time.Second * 3599, false},
+		{"numeric expires_in", `{"access_token":"t","expires_in":1800}`, time.Second * 1800, false},
+		{"missing expires_in", `{"access_token":"t"}`, DefaultTokenLifetime, false},
+		{"empty expires_in", `{"access_token":"t","expires_in":""}`, DefaultTokenLifetime, false},
+		{"invalid expires_in", `{"access_token":"t","expires_in":"soon"}`, 0, true},
+		{"missing access_token", `{"expires_in":"3599"}`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp models.AuthResponse
+			err := json.Unmarshal([]byte(tt.body), &resp)
+			if err == nil {
+				var token *Token
+				token, err = NewToken(&resp)
+				if err == nil {
+					assert.InDelta(t, tt.expectedLifetime.Seconds(), token.ExpiresIn().Seconds(), 1)
+				}
+			}
+			assert.Equal(t, tt.expectedError, err != nil)
+		})
+	}
+}
+
+func TestCachedTokenSourceExpiredHook(t *testing.T) {
+	var expired []string
+	fetches := 0
+	source := NewCachedTokenSource(func(ctx context.Context) (*Token, error) {
+		fetches++
+		return &Token{AccessToken: fmt.Sprintf("token_%d", fetches), Expiry: time.Now().Add(time.Second * 30)}, nil
+	}, time.Minute, WithExpiredHook(func(token *Token) {
+		expired = append(expired, token.AccessToken)
+	}))
+
+	assert.Nil(t, source.Current())
+
+	_, err := source.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, expired)
+
+	// token_1 is inside the refresh window, so it is replaced
+	_, err = source.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"token_1"}, expired)
+
+	source.Invalidate()
+	assert.Equal(t, []string{"token_1", "token_2"}, expired)
+	assert.Nil(t, source.Current())
+}
@@ -0,0 +1,229 @@
+package b2b
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/internal/transport"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/accountbalance"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/security"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
+)
+
+// CommandID identifies the kind of B2B payment
+type CommandID string
+
+const (
+	// BusinessPayBill pays into a paybill number with an account reference
+	BusinessPayBill CommandID = "BusinessPayBill"
+	// BusinessBuyGoods pays a till number
+	BusinessBuyGoods           CommandID = "BusinessBuyGoods"
+	DisburseFundsToBusiness    CommandID = "DisburseFundsToBusiness"
+	BusinessToBusinessTransfer CommandID = "BusinessToBusinessTransfer"
+	// MerchantToMerchantTransfer moves funds from one merchant account to another
+	MerchantToMerchantTransfer CommandID = "MerchantToMerchantTransfer"
+	// MerchantTransferFromMerchantToWorking moves funds from a merchant's
+	// merchant account to its own working account
+	MerchantTransferFromMerchantToWorking CommandID = "MerchantTransferFromMerchantToWorking"
+)
+
+// Identifier types of the sending and receiving organizations
+const (
+	IdentifierMSISDN    = "1"
+	IdentifierTill      = "2"
+	IdentifierShortCode = "4"
+)
+
+type B2BService struct {
+	client interface {
+		DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+	}
+}
+
+func NewB2BService(client interface {
+	DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+}) *B2BService {
+	return &B2BService{
+		client: client,
+	}
+}
+
+// PaymentRequest represents a B2B payment request
+type PaymentRequest struct {
+	Initiator              string    `json:"Initiator"`
+	SecurityCredential     string    `json:"SecurityCredential"`
+	CommandID              CommandID `json:"CommandID"`
+	SenderIdentifierType   string    `json:"SenderIdentifierType"`
+	RecieverIdentifierType string    `json:"RecieverIdentifierType"`
+	Amount                 string    `json:"Amount"`
+	PartyA                 string    `json:"PartyA"`
+	PartyB                 string    `json:"PartyB"`
+	AccountReference       string    `json:"AccountReference"`
+	// Requester is the phone number of the customer paying on behalf of the business, if any
+	Requester       string `json:"Requester,omitempty"`
+	Remarks         string `json:"Remarks"`
+	QueueTimeOutURL string `json:"QueueTimeOutURL"`
+	ResultURL       string `json:"ResultURL"`
+	Occasion        string `json:"Occassion,omitempty"`
+
+	// InitiatorPassword is encrypted into SecurityCredential when the latter is empty
+	InitiatorPassword string `json:"-"`
+}
+
+// SetInitiator fills the initiator name and credentials from initiator
+func (r *PaymentRequest) SetInitiator(initiator models.Initiator) {
+	r.Initiator = initiator.Identifier
+	r.SecurityCredential = initiator.SecurityCredential
+	r.InitiatorPassword = initiator.InitiatorPassword
+}
+
+// SetReceiver fills PartyB and RecieverIdentifierType from receiver
+func (r *PaymentRequest) SetReceiver(receiver models.ReceiverParty) {
+	r.PartyB = receiver.ShortCode
+	if r.PartyB == "" {
+		r.PartyB = receiver.Identifier
+	}
+	if receiver.IdentifierType != 0 {
+		r.RecieverIdentifierType = strconv.Itoa(receiver.IdentifierType)
+	}
+}
+
+// PaymentResponse represents the synchronous acknowledgement of a B2B payment
+type PaymentResponse struct {
+	ConversationID           string `json:"ConversationID"`
+	OriginatorConversationID string `json:"OriginatorConversationID"`
+	ResponseCode             string `json:"ResponseCode"`
+	ResponseDescription      string `json:"ResponseDescription"`
+}
+
+// PaymentResult represents the asynchronous result of a B2B payment
+type PaymentResult struct {
+	models.Result
+
+	Amount                  float64
+	Currency                string
+	DebitPartyCharges       string
+	ReceiverPartyPublicName string
+	TransCompletedTime      string
+	// DebitAccountBalance is the balance of the paying shortcode after the payment
+	DebitAccountBalance            Balance
+	InitiatorAccountCurrentBalance Balance
+	// DebitPartyAffectedAccountBalance lists the accounts of the paying shortcode
+	DebitPartyAffectedAccountBalance []accountbalance.Account
+	BillReferenceNumber              string
+}
+
+// Balance is a single amount reported in the form
+// "{Amount={BasicAmount=46713.00, MinimumAmount=4671300, CurrencyCode=KES}}"
+type Balance struct {
+	Currency string
+	Amount   float64
+}
+
+// ParseBalance parses a balance reported in a B2B result
+func ParseBalance(s string) (Balance, error) {
+	var balance Balance
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '{' || r == '}' || r == ','
+	})
+	for _, field := range fields {
+		key, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch key {
+		case "CurrencyCode":
+			balance.Currency = value
+		case "BasicAmount":
+			amount, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return Balance{}, fmt.Errorf("invalid balance %q: %w", s, err)
+			}
+			balance.Amount = amount
+		}
+	}
+	return balance, nil
+}
+
+// ProcessPayment sends money from a shortcode to another business
+func (s *B2BService) ProcessPayment(req *PaymentRequest) (*PaymentResponse, error) {
+	return s.ProcessPaymentContext(context.Background(), req)
+}
+
+// ProcessPaymentContext is like ProcessPayment but uses ctx for cancellation and deadlines
+func (s *B2BService) ProcessPaymentContext(ctx context.Context, req *PaymentRequest) (*PaymentResponse, error) {
+	if req.CommandID == "" {
+		req.CommandID = BusinessPayBill
+	}
+	if req.SenderIdentifierType == "" {
+		req.SenderIdentifierType = IdentifierShortCode
+	}
+	if req.RecieverIdentifierType == "" {
+		req.RecieverIdentifierType = IdentifierShortCode
+	}
+
+	credential, err := security.ResolveCredential(s.client, req.SecurityCredential, req.InitiatorPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate security credential: %w", err)
+	}
+	req.SecurityCredential = credential
+
+	endpoint := "/mpesa/b2b/v1/paymentrequest"
+	resp, err := transport.Do(telemetry.WithOperation(ctx, "b2b.payment"), s.client, "POST", endpoint, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process B2B payment: %w", err)
+	}
+
+	var payResp PaymentResponse
+	if err := json.Unmarshal(resp, &payResp); err != nil {
+		return nil, fmt.Errorf("failed to parse B2B payment response: %w", err)
+	}
+
+	return &payResp, nil
+}
+
+// ParseResult decodes the result M-PESA posts to the ResultURL of a B2B payment
+func ParseResult(r io.Reader) (*PaymentResult, error) {
+	result, err := models.ParseResult(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse B2B result: %w", err)
+	}
+
+	payResult := &PaymentResult{Result: *result}
+	params := result.ResultParameters
+
+	payResult.Currency, _ = params.Get("Currency")
+	payResult.DebitPartyCharges, _ = params.Get("DebitPartyCharges")
+	payResult.ReceiverPartyPublicName, _ = params.Get("ReceiverPartyPublicName")
+	payResult.TransCompletedTime, _ = params.Get("TransCompletedTime")
+	if payResult.Amount, err = params.Float("Amount"); err != nil {
+		return nil, fmt.Errorf("failed to parse B2B result: %w", err)
+	}
+
+	balances := map[string]*Balance{
+		"DebitAccountBalance":            &payResult.DebitAccountBalance,
+		"InitiatorAccountCurrentBalance": &payResult.InitiatorAccountCurrentBalance,
+	}
+	for key, dst := range balances {
+		if v, ok := params.Get(key); ok && v != "" {
+			if *dst, err = ParseBalance(v); err != nil {
+				return nil, fmt.Errorf("failed to parse B2B result: %w", err)
+			}
+		}
+	}
+	if v, ok := params.Get("DebitPartyAffectedAccountBalance"); ok && v != "" {
+		if payResult.DebitPartyAffectedAccountBalance, err = accountbalance.ParseAccountBalance(v); err != nil {
+			return nil, fmt.Errorf("failed to parse B2B result: %w", err)
+		}
+	}
+
+	for _, item := range result.ReferenceData.ReferenceItem {
+		if item.Key == "BillReferenceNumber" {
+			payResult.BillReferenceNumber = item.Value
+		}
+	}
+
+	return payResult, nil
+}
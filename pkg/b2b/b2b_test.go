@@ -0,0 +1,169 @@
+package b2b
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/accountbalance"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct {
+	doRequestFunc func(method, endpoint string, body interface{}) ([]byte, error)
+}
+
+func (m *mockClient) DoRequest(method, endpoint string, body interface{}) ([]byte, error) {
+	return m.doRequestFunc(method, endpoint, body)
+}
+
+func TestProcessPayment(t *testing.T) {
+	tests := []struct {
+		name           string
+		request        *PaymentRequest
+		mockResponse   []byte
+		mockError      error
+		expectedResult *PaymentResponse
+		expectedError  error
+	}{
+		{
+			name: "successful payment",
+			request: &PaymentRequest{
+				Initiator:          "testapi",
+				SecurityCredential: "credential",
+				Amount:             "10",
+				PartyA:             "101010",
+				PartyB:             "202020",
+				AccountReference:   "INV-001",
+			},
+			mockResponse: json.RawMessage(`{
+				"ConversationID": "AG_20240101_1234",
+				"OriginatorConversationID": "5678",
+				"ResponseCode": "0",
+				"ResponseDescription": "Accept the service request successfully."
+			}`),
+			expectedResult: &PaymentResponse{
+				ConversationID:           "AG_20240101_1234",
+				OriginatorConversationID: "5678",
+				ResponseCode:             "0",
+				ResponseDescription:      "Accept the service request successfully.",
+			},
+		},
+		{
+			name:          "failed payment",
+			request:       &PaymentRequest{CommandID: BusinessBuyGoods, SecurityCredential: "credential"},
+			mockError:     errors.New("connection refused"),
+			expectedError: errors.New("failed to process B2B payment: connection refused"),
+		},
+		{
+			name:          "invalid response",
+			request:       &PaymentRequest{CommandID: BusinessBuyGoods, SecurityCredential: "credential"},
+			mockResponse:  json.RawMessage(`invalid response`),
+			expectedError: errors.New("failed to parse B2B payment response: invalid character 'i' looking for beginning of value"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockClient{
+				doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+					assert.Equal(t, "/mpesa/b2b/v1/paymentrequest", endpoint)
+					req := body.(*PaymentRequest)
+					assert.NotEmpty(t, req.CommandID)
+					assert.Equal(t, IdentifierShortCode, req.SenderIdentifierType)
+					assert.Equal(t, IdentifierShortCode, req.RecieverIdentifierType)
+					return tt.mockResponse, tt.mockError
+				},
+			}
+
+			service := NewB2BService(mockClient)
+			result, err := service.ProcessPayment(tt.request)
+
+			if tt.expectedError != nil {
+				assert.EqualError(t, err, tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
+
+func TestPaymentRequestParties(t *testing.T) {
+	var req PaymentRequest
+	req.SetInitiator(models.Initiator{Identifier: "testapi", InitiatorPassword: "secret"})
+	req.SetReceiver(models.ReceiverParty{IdentifierType: 2, Identifier: "303030"})
+
+	assert.Equal(t, "testapi", req.Initiator)
+	assert.Equal(t, "secret", req.InitiatorPassword)
+	assert.Equal(t, "303030", req.PartyB)
+	assert.Equal(t, IdentifierTill, req.RecieverIdentifierType)
+
+	body, err := json.Marshal(&req)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "secret")
+}
+
+func TestParseResult(t *testing.T) {
+	payload := `{
+		"Result": {
+			"ResultType": 0,
+			"ResultCode": 0,
+			"ResultDesc": "The service request is processed successfully.",
+			"OriginatorConversationID": "5678",
+			"ConversationID": "AG_20240101_1234",
+			"TransactionID": "QKA81LK5CY",
+			"ResultParameters": {
+				"ResultParameter": [
+					{"Key": "DebitAccountBalance", "Value": "{Amount={CurrencyCode=KES, MinimumAmount=618683, BasicAmount=6186.83}}"},
+					{"Key": "Amount", "Value": 190.00},
+					{"Key": "DebitPartyAffectedAccountBalance", "Value": "Working Account|KES|346568.83|6186.83|340382.00|0.00"},
+					{"Key": "TransCompletedTime", "Value": 20221110110717},
+					{"Key": "DebitPartyCharges", "Value": ""},
+					{"Key": "ReceiverPartyPublicName", "Value": "000000 - Biller Company"},
+					{"Key": "Currency", "Value": "KES"},
+					{"Key": "InitiatorAccountCurrentBalance", "Value": "{Amount={CurrencyCode=KES, MinimumAmount=618683, BasicAmount=6186.83}}"}
+				]
+			},
+			"ReferenceData": {
+				"ReferenceItem": [
+					{"Key": "BillReferenceNumber", "Value": "INV-001"},
+					{"Key": "QueueTimeoutURL", "Value": "https://example.com/timeout"}
+				]
+			}
+		}
+	}`
+
+	result, err := ParseResult(strings.NewReader(payload))
+	assert.NoError(t, err)
+	assert.Equal(t, "QKA81LK5CY", result.TransactionID)
+	assert.Equal(t, 190.0, result.Amount)
+	assert.Equal(t, "KES", result.Currency)
+	assert.Equal(t, "20221110110717", result.TransCompletedTime)
+	assert.Equal(t, "000000 - Biller Company", result.ReceiverPartyPublicName)
+	assert.Equal(t, Balance{Currency: "KES", Amount: 6186.83}, result.DebitAccountBalance)
+	assert.Equal(t, Balance{Currency: "KES", Amount: 6186.83}, result.InitiatorAccountCurrentBalance)
+	assert.Equal(t, []accountbalance.Account{{
+		Name:             "Working Account",
+		Currency:         "KES",
+		CurrentBalance:   346568.83,
+		AvailableBalance: 6186.83,
+		ReservedBalance:  340382.00,
+	}}, result.DebitPartyAffectedAccountBalance)
+	assert.Equal(t, "INV-001", result.BillReferenceNumber)
+
+	_, err = ParseResult(strings.NewReader(`{"Result": {}}`))
+	assert.Error(t, err)
+}
+
+func TestParseBalance(t *testing.T) {
+	balance, err := ParseBalance("{Amount={BasicAmount=46713.00, MinimumAmount=4671300, CurrencyCode=KES}}")
+	assert.NoError(t, err)
+	assert.Equal(t, Balance{Currency: "KES", Amount: 46713}, balance)
+
+	_, err = ParseBalance("{Amount={BasicAmount=abc}}")
+	assert.Error(t, err)
+}
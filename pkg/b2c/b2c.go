@@ -0,0 +1,139 @@
+package b2c
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/internal/transport"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/security"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
+)
+
+// CommandID identifies the kind of B2C payment
+type CommandID string
+
+const (
+	BusinessPayment  CommandID = "BusinessPayment"
+	SalaryPayment    CommandID = "SalaryPayment"
+	PromotionPayment CommandID = "PromotionPayment"
+)
+
+type B2CService struct {
+	client interface {
+		DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+	}
+}
+
+func NewB2CService(client interface {
+	DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+}) *B2CService {
+	return &B2CService{
+		client: client,
+	}
+}
+
+// PaymentRequest represents a B2C payment request
+type PaymentRequest struct {
+	OriginatorConversationID string    `json:"OriginatorConversationID,omitempty"`
+	InitiatorName            string    `json:"InitiatorName"`
+	SecurityCredential       string    `json:"SecurityCredential"`
+	CommandID                CommandID `json:"CommandID"`
+	Amount                   string    `json:"Amount"`
+	PartyA                   string    `json:"PartyA"`
+	PartyB                   string    `json:"PartyB"`
+	Remarks                  string    `json:"Remarks"`
+	QueueTimeOutURL          string    `json:"QueueTimeOutURL"`
+	ResultURL                string    `json:"ResultURL"`
+	Occasion                 string    `json:"Occassion"`
+
+	// InitiatorPassword is encrypted into SecurityCredential when the latter is empty
+	InitiatorPassword string `json:"-"`
+}
+
+// PaymentResponse represents the synchronous acknowledgement of a B2C payment
+type PaymentResponse struct {
+	ConversationID           string `json:"ConversationID"`
+	OriginatorConversationID string `json:"OriginatorConversationID"`
+	ResponseCode             string `json:"ResponseCode"`
+	ResponseDescription      string `json:"ResponseDescription"`
+}
+
+// PaymentResult represents the asynchronous result of a B2C payment
+type PaymentResult struct {
+	models.Result
+
+	TransactionAmount                   float64
+	TransactionReceipt                  string
+	ReceiverPartyPublicName             string
+	TransactionCompletedDateTime        string
+	B2CRecipientIsRegisteredCustomer    bool
+	B2CChargesPaidAccountAvailableFunds float64
+	B2CUtilityAccountAvailableFunds     float64
+	B2CWorkingAccountAvailableFunds     float64
+}
+
+// ProcessPayment sends money from a shortcode to a customer
+func (s *B2CService) ProcessPayment(req *PaymentRequest) (*PaymentResponse, error) {
+	return s.ProcessPaymentContext(context.Background(), req)
+}
+
+// ProcessPaymentContext is like ProcessPayment but uses ctx for cancellation and deadlines
+func (s *B2CService) ProcessPaymentContext(ctx context.Context, req *PaymentRequest) (*PaymentResponse, error) {
+	if req.CommandID == "" {
+		req.CommandID = BusinessPayment
+	}
+
+	credential, err := security.ResolveCredential(s.client, req.SecurityCredential, req.InitiatorPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate security credential: %w", err)
+	}
+	req.SecurityCredential = credential
+
+	endpoint := "/mpesa/b2c/v2/paymentrequest"
+	resp, err := transport.Do(telemetry.WithOperation(ctx, "b2c.payment"), s.client, "POST", endpoint, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process B2C payment: %w", err)
+	}
+
+	var payResp PaymentResponse
+	if err := json.Unmarshal(resp, &payResp); err != nil {
+		return nil, fmt.Errorf("failed to parse B2C payment response: %w", err)
+	}
+
+	return &payResp, nil
+}
+
+// ParseResult decodes the result M-PESA posts to the ResultURL of a B2C payment
+func ParseResult(r io.Reader) (*PaymentResult, error) {
+	result, err := models.ParseResult(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse B2C result: %w", err)
+	}
+
+	payResult := &PaymentResult{Result: *result}
+	params := result.ResultParameters
+
+	payResult.TransactionReceipt, _ = params.Get("TransactionReceipt")
+	payResult.ReceiverPartyPublicName, _ = params.Get("ReceiverPartyPublicName")
+	payResult.TransactionCompletedDateTime, _ = params.Get("TransactionCompletedDateTime")
+	if v, ok := params.Get("B2CRecipientIsRegisteredCustomer"); ok {
+		payResult.B2CRecipientIsRegisteredCustomer = v == "Y"
+	}
+
+	amounts := map[string]*float64{
+		"TransactionAmount":                   &payResult.TransactionAmount,
+		"B2CChargesPaidAccountAvailableFunds": &payResult.B2CChargesPaidAccountAvailableFunds,
+		"B2CUtilityAccountAvailableFunds":     &payResult.B2CUtilityAccountAvailableFunds,
+		"B2CWorkingAccountAvailableFunds":     &payResult.B2CWorkingAccountAvailableFunds,
+	}
+	for key, dst := range amounts {
+		if *dst, err = params.Float(key); err != nil {
+			return nil, fmt.Errorf("failed to parse B2C result: %w", err)
+		}
+	}
+
+	return payResult, nil
+}
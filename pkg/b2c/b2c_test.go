@@ -0,0 +1,130 @@
+package b2c
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct {
+	doRequestFunc func(method, endpoint string, body interface{}) ([]byte, error)
+}
+
+func (m *mockClient) DoRequest(method, endpoint string, body interface{}) ([]byte, error) {
+	return m.doRequestFunc(method, endpoint, body)
+}
+
+func TestProcessPayment(t *testing.T) {
+	tests := []struct {
+		name           string
+		request        *PaymentRequest
+		mockResponse   []byte
+		mockError      error
+		expectedResult *PaymentResponse
+		expectedError  error
+	}{
+		{
+			name: "successful payment",
+			request: &PaymentRequest{
+				InitiatorName: "testapi",
+				Amount:        "10",
+				PartyA:        "101010",
+				PartyB:        "251700404789",
+			},
+			mockResponse: json.RawMessage(`{
+				"ConversationID": "AG_20240101_1234",
+				"OriginatorConversationID": "5678",
+				"ResponseCode": "0",
+				"ResponseDescription": "Accept the service request successfully."
+			}`),
+			expectedResult: &PaymentResponse{
+				ConversationID:           "AG_20240101_1234",
+				OriginatorConversationID: "5678",
+				ResponseCode:             "0",
+				ResponseDescription:      "Accept the service request successfully.",
+			},
+		},
+		{
+			name:          "failed payment",
+			request:       &PaymentRequest{CommandID: SalaryPayment},
+			mockError:     errors.New("connection refused"),
+			expectedError: errors.New("failed to process B2C payment: connection refused"),
+		},
+		{
+			name:          "invalid response",
+			request:       &PaymentRequest{CommandID: SalaryPayment},
+			mockResponse:  json.RawMessage(`invalid response`),
+			expectedError: errors.New("failed to parse B2C payment response: invalid character 'i' looking for beginning of value"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockClient{
+				doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+					assert.Equal(t, "/mpesa/b2c/v2/paymentrequest", endpoint)
+					assert.NotEmpty(t, body.(*PaymentRequest).CommandID)
+					return tt.mockResponse, tt.mockError
+				},
+			}
+
+			service := NewB2CService(mockClient)
+			result, err := service.ProcessPayment(tt.request)
+
+			if tt.expectedError != nil {
+				assert.EqualError(t, err, tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
+
+func TestParseResult(t *testing.T) {
+	payload := `{
+		"Result": {
+			"ResultType": 0,
+			"ResultCode": 0,
+			"ResultDesc": "The service request is processed successfully.",
+			"OriginatorConversationID": "5678",
+			"ConversationID": "AG_20240101_1234",
+			"TransactionID": "NLJ41HAY6Q",
+			"ResultParameters": {
+				"ResultParameter": [
+					{"Key": "TransactionAmount", "Value": 10},
+					{"Key": "TransactionReceipt", "Value": "NLJ41HAY6Q"},
+					{"Key": "B2CRecipientIsRegisteredCustomer", "Value": "Y"},
+					{"Key": "B2CChargesPaidAccountAvailableFunds", "Value": -4510.00},
+					{"Key": "ReceiverPartyPublicName", "Value": "251700404789 - John Doe"},
+					{"Key": "TransactionCompletedDateTime", "Value": "19.12.2019 11:45:50"},
+					{"Key": "B2CUtilityAccountAvailableFunds", "Value": 10116.00},
+					{"Key": "B2CWorkingAccountAvailableFunds", "Value": 900000.00}
+				]
+			},
+			"ReferenceData": {
+				"ReferenceItem": {"Key": "QueueTimeoutURL", "Value": "https://internalsandbox.safaricom.co.ke/mpesa/b2cresults/v1/submit"}
+			}
+		}
+	}`
+
+	result, err := ParseResult(strings.NewReader(payload))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.ResultCode)
+	assert.Equal(t, "NLJ41HAY6Q", result.TransactionID)
+	assert.Equal(t, 10.0, result.TransactionAmount)
+	assert.Equal(t, "NLJ41HAY6Q", result.TransactionReceipt)
+	assert.True(t, result.B2CRecipientIsRegisteredCustomer)
+	assert.Equal(t, -4510.0, result.B2CChargesPaidAccountAvailableFunds)
+	assert.Equal(t, 10116.0, result.B2CUtilityAccountAvailableFunds)
+	assert.Equal(t, 900000.0, result.B2CWorkingAccountAvailableFunds)
+	assert.Equal(t, "251700404789 - John Doe", result.ReceiverPartyPublicName)
+	assert.Len(t, result.ReferenceData.ReferenceItem, 1)
+
+	_, err = ParseResult(strings.NewReader(`{"Result": {}}`))
+	assert.Error(t, err)
+}
@@ -1,10 +1,16 @@
 package c2b
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
+	"github.com/natnael-alemayehu/mpesa-sdk-go/internal/transport"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/config"
 	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/security"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
 )
 
 type C2BService struct {
@@ -21,6 +27,10 @@ func NewC2BService(client interface {
 	}
 }
 
+// ErrSimulationNotAllowed is returned by Simulate unless the client is
+// configured for the sandbox
+var ErrSimulationNotAllowed = errors.New("C2B simulation is only available in the sandbox")
+
 // RegisterURLRequest represents the request to register C2B callback URLs
 type RegisterURLRequest struct {
 	ShortCode       string `json:"ShortCode"`
@@ -64,8 +74,30 @@ type PaymentResponse struct {
 	AdditionalInfo []string `json:"AdditionalInfo"`
 }
 
+// SimulateRequest represents a simulated customer payment in the sandbox
+type SimulateRequest struct {
+	ShortCode     string `json:"ShortCode"`
+	CommandID     string `json:"CommandID"`
+	Amount        string `json:"Amount"`
+	Msisdn        string `json:"Msisdn"`
+	BillRefNumber string `json:"BillRefNumber"`
+}
+
+// SimulateResponse represents the acknowledgement of a simulated payment
+type SimulateResponse struct {
+	ConversationID           string `json:"ConversationID"`
+	OriginatorConversationID string `json:"OriginatorConversationID"`
+	ResponseCode             string `json:"ResponseCode"`
+	ResponseDescription      string `json:"ResponseDescription"`
+}
+
 // RegisterURL registers the confirmation and validation URLs
 func (s *C2BService) RegisterURL(req *RegisterURLRequest) (*RegisterURLResponse, error) {
+	return s.RegisterURLContext(context.Background(), req)
+}
+
+// RegisterURLContext is like RegisterURL but uses ctx for cancellation and deadlines
+func (s *C2BService) RegisterURLContext(ctx context.Context, req *RegisterURLRequest) (*RegisterURLResponse, error) {
 	if req.CommandID == "" {
 		req.CommandID = "RegisterURL"
 	}
@@ -74,7 +106,7 @@ func (s *C2BService) RegisterURL(req *RegisterURLRequest) (*RegisterURLResponse,
 	}
 
 	endpoint := "/v1/c2b-register-url/register"
-	resp, err := s.client.DoRequest("POST", endpoint, req)
+	resp, err := transport.Do(telemetry.WithOperation(ctx, "c2b.register_url"), s.client, "POST", endpoint, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to register URLs: %w", err)
 	}
@@ -89,6 +121,11 @@ func (s *C2BService) RegisterURL(req *RegisterURLRequest) (*RegisterURLResponse,
 
 // ProcessPayment processes a C2B payment
 func (s *C2BService) ProcessPayment(req *PaymentRequest) (*PaymentResponse, error) {
+	return s.ProcessPaymentContext(context.Background(), req)
+}
+
+// ProcessPaymentContext is like ProcessPayment but uses ctx for cancellation and deadlines
+func (s *C2BService) ProcessPaymentContext(ctx context.Context, req *PaymentRequest) (*PaymentResponse, error) {
 	if req.CommandID == "" {
 		req.CommandID = "CustomerPayBillOnline"
 	}
@@ -96,8 +133,14 @@ func (s *C2BService) ProcessPayment(req *PaymentRequest) (*PaymentResponse, erro
 		req.SourceSystem = "USSD"
 	}
 
+	credential, err := security.ResolveCredential(s.client, req.Initiator.SecurityCredential, req.Initiator.InitiatorPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate security credential: %w", err)
+	}
+	req.Initiator.SecurityCredential = credential
+
 	endpoint := "/v1/c2b/payments"
-	resp, err := s.client.DoRequest("POST", endpoint, req)
+	resp, err := transport.Do(telemetry.WithOperation(ctx, "c2b.payment"), s.client, "POST", endpoint, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to process C2B payment: %w", err)
 	}
@@ -109,3 +152,36 @@ func (s *C2BService) ProcessPayment(req *PaymentRequest) (*PaymentResponse, erro
 
 	return &payResp, nil
 }
+
+// Simulate triggers a customer payment to a shortcode in the sandbox, which
+// calls the registered validation and confirmation URLs. It returns
+// ErrSimulationNotAllowed unless the client exposes its configuration and
+// that configuration is for the sandbox.
+func (s *C2BService) Simulate(req *SimulateRequest) (*SimulateResponse, error) {
+	return s.SimulateContext(context.Background(), req)
+}
+
+// SimulateContext is like Simulate but uses ctx for cancellation and deadlines
+func (s *C2BService) SimulateContext(ctx context.Context, req *SimulateRequest) (*SimulateResponse, error) {
+	p, ok := s.client.(config.ConfigProvider)
+	if !ok || p.Config() == nil || p.Config().Environment != config.Sandbox {
+		return nil, ErrSimulationNotAllowed
+	}
+
+	if req.CommandID == "" {
+		req.CommandID = "CustomerPayBillOnline"
+	}
+
+	endpoint := "/mpesa/b2c/simulatetransaction/v1/request"
+	resp, err := transport.Do(telemetry.WithOperation(ctx, "c2b.simulate"), s.client, "POST", endpoint, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate C2B payment: %w", err)
+	}
+
+	var simResp SimulateResponse
+	if err := json.Unmarshal(resp, &simResp); err != nil {
+		return nil, fmt.Errorf("failed to parse C2B simulation response: %w", err)
+	}
+
+	return &simResp, nil
+}
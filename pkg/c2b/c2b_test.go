@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,6 +17,16 @@ func (m *mockClient) DoRequest(method, endpoint string, body interface{}) ([]byt
 	return m.doRequestFunc(method, endpoint, body)
 }
 
+// configMockClient is a mockClient that exposes its configuration
+type configMockClient struct {
+	mockClient
+	config *config.Config
+}
+
+func (m *configMockClient) Config() *config.Config {
+	return m.config
+}
+
 func TestProcessPayment(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -96,3 +107,57 @@ func TestProcessPayment(t *testing.T) {
 		})
 	}
 }
+
+func TestSimulate(t *testing.T) {
+	var called bool
+	newClient := func(env config.Environment) *configMockClient {
+		return &configMockClient{
+			mockClient: mockClient{
+				doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+					called = true
+					assert.Equal(t, "/mpesa/b2c/simulatetransaction/v1/request", endpoint)
+					assert.Equal(t, "CustomerPayBillOnline", body.(*SimulateRequest).CommandID)
+					return json.RawMessage(`{
+						"ConversationID": "AG_20240101_1234",
+						"OriginatorConversationID": "5678",
+						"ResponseCode": "0",
+						"ResponseDescription": "Accept the service request successfully."
+					}`), nil
+				},
+			},
+			config: &config.Config{Environment: env},
+		}
+	}
+	req := func() *SimulateRequest {
+		return &SimulateRequest{
+			ShortCode:     "443443",
+			Amount:        "10",
+			Msisdn:        "251700404789",
+			BillRefNumber: "INV-001",
+		}
+	}
+
+	resp, err := NewC2BService(newClient(config.Sandbox)).Simulate(req())
+	assert.NoError(t, err)
+	assert.True(t, called)
+	assert.Equal(t, "0", resp.ResponseCode)
+	assert.Equal(t, "AG_20240101_1234", resp.ConversationID)
+
+	// Simulation is refused unless the client is known to be in the sandbox
+	refused := map[string]interface {
+		DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+	}{
+		"production":     newClient(config.Production),
+		"no environment": newClient(""),
+		"nil config":     &configMockClient{mockClient: newClient(config.Sandbox).mockClient},
+		"no config":      &newClient(config.Sandbox).mockClient,
+	}
+	for name, client := range refused {
+		t.Run(name, func(t *testing.T) {
+			called = false
+			_, err := NewC2BService(client).Simulate(req())
+			assert.True(t, errors.Is(err, ErrSimulationNotAllowed))
+			assert.False(t, called)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package c2b
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// TransactionNotification represents the payload M-PESA posts to both the
+// ValidationURL and the ConfirmationURL
+type TransactionNotification struct {
+	TransactionType   string `json:"TransactionType"`
+	TransID           string `json:"TransID"`
+	TransTime         string `json:"TransTime"`
+	TransAmount       string `json:"TransAmount"`
+	BusinessShortCode string `json:"BusinessShortCode"`
+	BillRefNumber     string `json:"BillRefNumber"`
+	InvoiceNumber     string `json:"InvoiceNumber"`
+	OrgAccountBalance string `json:"OrgAccountBalance"`
+	ThirdPartyTransID string `json:"ThirdPartyTransID"`
+	MSISDN            string `json:"MSISDN"`
+	FirstName         string `json:"FirstName"`
+	MiddleName        string `json:"MiddleName"`
+	LastName          string `json:"LastName"`
+}
+
+// ResultCode is the code returned to M-PESA in response to a notification
+type ResultCode string
+
+const (
+	ResultAccepted             ResultCode = "0"
+	ResultInvalidMSISDN        ResultCode = "C2B00011"
+	ResultInvalidAccountNumber ResultCode = "C2B00012"
+	ResultInvalidAmount        ResultCode = "C2B00013"
+	ResultInvalidKYCDetails    ResultCode = "C2B00014"
+	ResultInvalidShortcode     ResultCode = "C2B00015"
+	ResultOtherError           ResultCode = "C2B00016"
+)
+
+// NotificationResponse represents the response returned to M-PESA
+type NotificationResponse struct {
+	ResultCode ResultCode `json:"ResultCode"`
+	ResultDesc string     `json:"ResultDesc"`
+}
+
+// Accept returns a response that lets the transaction proceed
+func Accept() NotificationResponse {
+	return NotificationResponse{ResultCode: ResultAccepted, ResultDesc: "Accepted"}
+}
+
+// Reject returns a response that makes M-PESA cancel the transaction
+func Reject(code ResultCode) NotificationResponse {
+	return NotificationResponse{ResultCode: code, ResultDesc: "Rejected"}
+}
+
+// ParseNotification decodes a validation or confirmation payload
+func ParseNotification(r io.Reader) (*TransactionNotification, error) {
+	var n TransactionNotification
+	if err := json.NewDecoder(r).Decode(&n); err != nil {
+		return nil, fmt.Errorf("failed to parse C2B notification: %w", err)
+	}
+	if n.TransID == "" {
+		return nil, fmt.Errorf("failed to parse C2B notification: missing TransID")
+	}
+	return &n, nil
+}
+
+// ValidationHandler is an http.Handler for the C2B ValidationURL
+type ValidationHandler struct {
+	validate      func(ctx context.Context, n *TransactionNotification) NotificationResponse
+	timeout       time.Duration
+	defaultResult NotificationResponse
+}
+
+// ValidationOption defines a function type for validation handler options
+type ValidationOption func(*ValidationHandler)
+
+// NewValidationHandler creates a handler that asks validate whether to accept each transaction
+func NewValidationHandler(validate func(ctx context.Context, n *TransactionNotification) NotificationResponse, options ...ValidationOption) *ValidationHandler {
+	h := &ValidationHandler{
+		validate:      validate,
+		timeout:       time.Second * 5,
+		defaultResult: Accept(),
+	}
+
+	// Apply options
+	for _, option := range options {
+		option(h)
+	}
+
+	return h
+}
+
+// WithValidationTimeout sets how long the validator may take before the default result is sent
+func WithValidationTimeout(timeout time.Duration) ValidationOption {
+	return func(h *ValidationHandler) {
+		h.timeout = timeout
+	}
+}
+
+// WithDefaultResult sets the response sent when the validator times out or panics
+func WithDefaultResult(resp NotificationResponse) ValidationOption {
+	return func(h *ValidationHandler) {
+		h.defaultResult = resp
+	}
+}
+
+// ServeHTTP decodes the validation request and returns the validator's decision
+func (h *ValidationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	n, err := ParseNotification(r.Body)
+	if err != nil {
+		writeNotificationResponse(w, http.StatusBadRequest, Reject(ResultOtherError))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	result := make(chan NotificationResponse, 1)
+	go func() {
+		// net/http only recovers panics on the handler's goroutine, so a
+		// panicking validator would otherwise take down the server
+		defer func() {
+			if recover() != nil {
+				result <- h.defaultResult
+			}
+		}()
+		result <- h.validate(ctx, n)
+	}()
+
+	select {
+	case resp := <-result:
+		writeNotificationResponse(w, http.StatusOK, resp)
+	case <-ctx.Done():
+		writeNotificationResponse(w, http.StatusOK, h.defaultResult)
+	}
+}
+
+// ConfirmationHandler is an http.Handler for the C2B ConfirmationURL
+type ConfirmationHandler struct {
+	confirm func(ctx context.Context, n *TransactionNotification) error
+}
+
+// NewConfirmationHandler creates a handler that calls confirm for every completed transaction
+func NewConfirmationHandler(confirm func(ctx context.Context, n *TransactionNotification) error) *ConfirmationHandler {
+	return &ConfirmationHandler{
+		confirm: confirm,
+	}
+}
+
+// ServeHTTP decodes the confirmation request and acknowledges it to M-PESA
+func (h *ConfirmationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	n, err := ParseNotification(r.Body)
+	if err != nil {
+		writeNotificationResponse(w, http.StatusBadRequest, Reject(ResultOtherError))
+		return
+	}
+
+	if err := h.confirm(r.Context(), n); err != nil {
+		writeNotificationResponse(w, http.StatusInternalServerError, NotificationResponse{ResultCode: ResultOtherError, ResultDesc: "Failed"})
+		return
+	}
+
+	writeNotificationResponse(w, http.StatusOK, NotificationResponse{ResultCode: ResultAccepted, ResultDesc: "Success"})
+}
+
+func writeNotificationResponse(w http.ResponseWriter, status int, resp NotificationResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,140 @@
+package c2b
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const notificationPayload = `{
+	"TransactionType": "Pay Bill",
+	"TransID": "RKTQDM7W6S",
+	"TransTime": "20191122063845",
+	"TransAmount": "10",
+	"BusinessShortCode": "600638",
+	"BillRefNumber": "invoice008",
+	"InvoiceNumber": "",
+	"OrgAccountBalance": "",
+	"ThirdPartyTransID": "",
+	"MSISDN": "251700404789",
+	"FirstName": "John",
+	"MiddleName": "",
+	"LastName": "Doe"
+}`
+
+func serveNotification(t *testing.T, handler http.Handler, payload string) (int, NotificationResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/c2b", strings.NewReader(payload))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var resp NotificationResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec.Code, resp
+}
+
+func TestValidationHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		payload        string
+		validate       func(ctx context.Context, n *TransactionNotification) NotificationResponse
+		options        []ValidationOption
+		expectedStatus int
+		expectedResult NotificationResponse
+	}{
+		{
+			name:    "accepted",
+			payload: notificationPayload,
+			validate: func(ctx context.Context, n *TransactionNotification) NotificationResponse {
+				return Accept()
+			},
+			expectedStatus: http.StatusOK,
+			expectedResult: NotificationResponse{ResultCode: "0", ResultDesc: "Accepted"},
+		},
+		{
+			name:    "rejected",
+			payload: notificationPayload,
+			validate: func(ctx context.Context, n *TransactionNotification) NotificationResponse {
+				if n.BillRefNumber != "known" {
+					return Reject(ResultInvalidAccountNumber)
+				}
+				return Accept()
+			},
+			expectedStatus: http.StatusOK,
+			expectedResult: NotificationResponse{ResultCode: "C2B00012", ResultDesc: "Rejected"},
+		},
+		{
+			name:    "timeout uses default result",
+			payload: notificationPayload,
+			validate: func(ctx context.Context, n *TransactionNotification) NotificationResponse {
+				<-ctx.Done()
+				time.Sleep(time.Millisecond * 10)
+				return Accept()
+			},
+			options: []ValidationOption{
+				WithValidationTimeout(time.Millisecond * 10),
+				WithDefaultResult(Reject(ResultOtherError)),
+			},
+			expectedStatus: http.StatusOK,
+			expectedResult: NotificationResponse{ResultCode: "C2B00016", ResultDesc: "Rejected"},
+		},
+		{
+			name:    "panic uses default result",
+			payload: notificationPayload,
+			validate: func(ctx context.Context, n *TransactionNotification) NotificationResponse {
+				panic("validator failed")
+			},
+			options:        []ValidationOption{WithDefaultResult(Reject(ResultOtherError))},
+			expectedStatus: http.StatusOK,
+			expectedResult: NotificationResponse{ResultCode: "C2B00016", ResultDesc: "Rejected"},
+		},
+		{
+			name:    "invalid payload",
+			payload: `invalid`,
+			validate: func(ctx context.Context, n *TransactionNotification) NotificationResponse {
+				return Accept()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResult: NotificationResponse{ResultCode: "C2B00016", ResultDesc: "Rejected"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewValidationHandler(tt.validate, tt.options...)
+			status, resp := serveNotification(t, handler, tt.payload)
+
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Equal(t, tt.expectedResult, resp)
+		})
+	}
+}
+
+func TestConfirmationHandler(t *testing.T) {
+	var received *TransactionNotification
+	handler := NewConfirmationHandler(func(ctx context.Context, n *TransactionNotification) error {
+		received = n
+		return nil
+	})
+
+	status, resp := serveNotification(t, handler, notificationPayload)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, NotificationResponse{ResultCode: "0", ResultDesc: "Success"}, resp)
+	assert.Equal(t, "RKTQDM7W6S", received.TransID)
+	assert.Equal(t, "10", received.TransAmount)
+	assert.Equal(t, "251700404789", received.MSISDN)
+
+	failing := NewConfirmationHandler(func(ctx context.Context, n *TransactionNotification) error {
+		return errors.New("database unavailable")
+	})
+	status, _ = serveNotification(t, failing, notificationPayload)
+	assert.Equal(t, http.StatusInternalServerError, status)
+}
@@ -2,52 +2,141 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"time"
 
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/auth"
 	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/config"
 	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
 )
 
 // Client represents the M-PESA API client
 type Client struct {
 	config     *config.Config
 	httpClient *http.Client
-	token      string
-	tokenExp   time.Time
+	tokens     auth.TokenSource
+	logger     *slog.Logger
+	tracer     telemetry.Tracer
+	meter      telemetry.Meter
+	doer       Doer
+	middleware []Middleware
+	baseURL    string
+	userAgent  string
+	headers    http.Header
 }
 
 // NewClient creates a new M-PESA API client
-func NewClient(cfg *config.Config) *Client {
-	return &Client{
+func NewClient(cfg *config.Config, options ...ClientOption) *Client {
+	c := &Client{
 		config: cfg,
 		httpClient: &http.Client{
 			Timeout: cfg.Timeout,
 		},
+		baseURL:   cfg.BaseURL,
+		userAgent: DefaultUserAgent,
+		headers:   make(http.Header),
+		logger:    cfg.Logger,
+		tracer:    telemetry.NoopTracer{},
+		meter:     telemetry.NoopMeter{},
 	}
+	if c.logger == nil {
+		c.logger = slog.New(discardHandler{})
+	}
+
+	// Apply options
+	for _, option := range options {
+		option(c)
+	}
+
+	c.doer = chain(DoerFunc(c.doRequest), c.middleware)
+
+	hook := auth.WithExpiredHook(cfg.OnTokenExpired)
+	if cfg.TokenStore != nil {
+		c.tokens = auth.NewSharedTokenSource(cfg.TokenStore, tokenKey(c.baseURL, cfg.ConsumerKey), c.fetchToken, cfg.TokenRefreshSkew, hook)
+	} else {
+		c.tokens = auth.NewCachedTokenSource(c.fetchToken, cfg.TokenRefreshSkew, hook)
+	}
+	return c
+}
+
+// tokenKey identifies the tokens of a consumer key in a shared TokenStore
+// without exposing it
+func tokenKey(baseURL, consumerKey string) string {
+	sum := sha256.Sum256([]byte(baseURL + "|" + consumerKey))
+	return "mpesa:token:" + hex.EncodeToString(sum[:16])
+}
+
+// Config returns the configuration the client was created with
+func (c *Client) Config() *config.Config {
+	return c.config
+}
+
+// Token returns the current access token without fetching one, or nil if
+// none has been fetched yet; its ExpiresIn method reports the remaining lifetime
+func (c *Client) Token() *auth.Token {
+	if tokens, ok := c.tokens.(interface{ Current() *auth.Token }); ok {
+		return tokens.Current()
+	}
+	return nil
+}
+
+// TokenSource returns the source the client takes its access tokens from, so
+// that other components can share the client's token cache
+func (c *Client) TokenSource() auth.TokenSource {
+	return c.tokens
 }
 
 // GetToken authenticates with the M-PESA API and gets an access token
 func (c *Client) GetToken() error {
-	// Check if current token is still valid
-	if c.token != "" && time.Now().Before(c.tokenExp) {
-		return nil
+	return c.GetTokenContext(context.Background())
+}
+
+// GetTokenContext is like GetToken but aborts the token request when ctx is done
+func (c *Client) GetTokenContext(ctx context.Context) error {
+	_, err := c.accessToken(ctx)
+	return err
+}
+
+// accessToken returns a valid access token, fetching one if needed
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return "", err
 	}
+	return token.AccessToken, nil
+}
 
+// fetchToken requests a new access token, tracing the call
+func (c *Client) fetchToken(ctx context.Context) (*auth.Token, error) {
+	ctx = telemetry.WithOperation(ctx, "token.generate")
+	ctx, span, call := c.startCall(ctx, http.MethodGet, "/v1/token/generate")
+	start := time.Now()
+	token, err := c.requestToken(ctx, call)
+	c.endCall(ctx, span, call, start, nil, err)
+	return token, err
+}
+
+// requestToken requests a new access token from the token endpoint
+func (c *Client) requestToken(ctx context.Context, call *callInfo) (*auth.Token, error) {
 	// Create basic auth string
-	auth := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s",
+	credentials := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s",
 		c.config.ConsumerKey, c.config.ConsumerSecret)))
 
 	// Create URL with query parameters
-	baseURL := c.config.BaseURL + "/v1/token/generate"
-	u, err := url.Parse(baseURL)
+	u, err := url.Parse(c.baseURL + "/v1/token/generate")
 	if err != nil {
-		return fmt.Errorf("error parsing URL: %w", err)
+		return nil, fmt.Errorf("error parsing URL: %w", err)
 	}
 
 	q := u.Query()
@@ -55,131 +144,321 @@ func (c *Client) GetToken() error {
 	u.RawQuery = q.Encode()
 
 	// Create request
-	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		return fmt.Errorf("error creating auth request: %w", err)
+		return nil, fmt.Errorf("error creating auth request: %w", err)
 	}
 
 	// Set basic auth header
-	req.Header.Set("Authorization", "Basic "+auth)
+	c.setHeaders(req)
+	req.Header.Set("Authorization", "Basic "+credentials)
 
 	// Make request
+	start := time.Now()
+	call.attempts = 1
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("error making auth request: %w", err)
+		c.logger.DebugContext(ctx, "mpesa token request failed",
+			slog.Duration("latency", time.Since(start)),
+			slog.Any("error", err))
+		return nil, fmt.Errorf("error making auth request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("error reading auth response: %w", err)
+		return nil, fmt.Errorf("error reading auth response: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		// Handle specific error codes
-		var errorResp struct {
-			ResultCode string `json:"resultCode"`
-			ResultDesc string `json:"resultDesc"`
-		}
-		if err := json.Unmarshal(respBody, &errorResp); err != nil {
-			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
-		}
-
-		switch errorResp.ResultCode {
-		case "999991":
-			return fmt.Errorf("invalid client ID: %s", errorResp.ResultDesc)
-		case "999996":
-			return fmt.Errorf("invalid authentication type: %s", errorResp.ResultDesc)
-		case "999997":
-			return fmt.Errorf("invalid authorization header: %s", errorResp.ResultDesc)
-		case "999998":
-			return fmt.Errorf("invalid grant type: %s", errorResp.ResultDesc)
-		default:
-			return fmt.Errorf("authentication error: %s - %s", errorResp.ResultCode, errorResp.ResultDesc)
-		}
+	if c.debugEnabled(ctx) {
+		c.logger.DebugContext(ctx, "mpesa token response",
+			slog.Int("status", resp.StatusCode),
+			slog.Duration("latency", time.Since(start)),
+			slog.Any("headers", redactHeader(req.Header)),
+			slog.String("body", redactJSON(respBody)))
 	}
 
-	// Parse successful response
-	var tokenResp struct {
-		AccessToken string `json:"access_token"`
-		TokenType   string `json:"token_type"`
-		ExpiresIn   string `json:"expires_in"`
+	call.statusCode = resp.StatusCode
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAuthError(resp.StatusCode, respBody)
 	}
 
+	// Parse successful response
+	var tokenResp models.AuthResponse
 	if err := json.Unmarshal(respBody, &tokenResp); err != nil {
-		return fmt.Errorf("error parsing auth response: %w", err)
+		return nil, fmt.Errorf("error parsing auth response: %w", err)
 	}
 
-	// Store token and expiration
-	c.token = tokenResp.AccessToken
-	expiresIn := 3599 // default to 1 hour - 1 second if parsing fails
-	if tokenResp.ExpiresIn != "" {
-		if exp, err := time.ParseDuration(tokenResp.ExpiresIn + "s"); err == nil {
-			expiresIn = int(exp.Seconds())
-		}
+	token, err := auth.NewToken(&tokenResp)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing auth response: %w", err)
 	}
-	c.tokenExp = time.Now().Add(time.Duration(expiresIn) * time.Second)
-
-	return nil
+	return token, nil
 }
 
 // DoRequest performs an HTTP request with authentication and retries
 func (c *Client) DoRequest(method, endpoint string, body interface{}) ([]byte, error) {
+	return c.DoRequestContext(context.Background(), method, endpoint, body)
+}
+
+// DoRequestContext is like DoRequest but propagates ctx into the token fetch,
+// every HTTP attempt and the waits between retries. The call passes through
+// the middleware configured with WithMiddleware.
+func (c *Client) DoRequestContext(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+	return c.doer.DoRequestContext(ctx, method, endpoint, body)
+}
+
+// doRequest is the innermost Doer, tracing the call and recording its metrics
+func (c *Client) doRequest(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+	ctx, span, call := c.startCall(ctx, method, endpoint)
+	start := time.Now()
+	respBody, err := c.doWithRetry(ctx, call, method, endpoint, body)
+	c.endCall(ctx, span, call, start, respBody, err)
+	return respBody, err
+}
+
+// doWithRetry performs the call, retrying failed attempts as the policy allows
+func (c *Client) doWithRetry(ctx context.Context, call *callInfo, method, endpoint string, body interface{}) ([]byte, error) {
 	// Get/refresh token if needed
-	if err := c.GetToken(); err != nil {
+	token, err := c.accessToken(ctx)
+	if err != nil {
 		return nil, fmt.Errorf("error getting access token: %w", err)
 	}
 
-	var bodyReader io.Reader
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("error marshaling request body: %w", err)
 		}
-		bodyReader = bytes.NewReader(jsonBody)
 	}
 
-	req, err := http.NewRequest(method, c.config.BaseURL+endpoint, bodyReader)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+	if c.debugEnabled(ctx) {
+		c.logger.DebugContext(ctx, "mpesa request",
+			slog.String("method", method),
+			slog.String("endpoint", endpoint),
+			slog.String("body", redactJSON(jsonBody)))
 	}
 
-	// Set common headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.token)
-
 	// Implement retry logic
+	policy := c.retryPolicy()
+	idempotent := isIdempotent(method) || isRetrySafe(ctx)
+	start := time.Now()
+	tokenRefreshed := false
+
+	// attempt counts the attempts the retry policy has seen; a resend after a
+	// token refresh does not use up a retry, but it is still counted as a
+	// request sent in call.attempts
 	var lastErr error
-	for i := 0; i <= c.config.RetryCount; i++ {
-		resp, err := c.httpClient.Do(req)
+	attempt := 1
+	for {
+		retry := config.RetryAttempt{
+			Attempt:    attempt,
+			Method:     method,
+			Idempotent: idempotent,
+		}
+
+		// Every attempt gets a fresh request so the body is sent in full and
+		// the Authorization header carries the current token
+		req, err := c.newRequest(ctx, method, endpoint, token, jsonBody)
 		if err != nil {
-			lastErr = err
-			time.Sleep(c.config.RetryWaitTime)
-			continue
+			return nil, err
 		}
-		defer resp.Body.Close()
 
-		respBody, err := io.ReadAll(resp.Body)
+		sent := time.Now()
+		resp, respBody, err := c.send(req)
+		call.attempts++
+		c.logAttempt(ctx, req, call.attempts, time.Since(sent), resp, respBody, err)
+		if resp != nil {
+			call.statusCode = resp.StatusCode
+		}
 		if err != nil {
-			return nil, fmt.Errorf("error reading response body: %w", err)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = err
+			retry.Err = err
+		} else {
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				return respBody, nil
+			}
+
+			// Handle error responses
+			apiErr := newAPIError(resp.StatusCode, respBody)
+			lastErr = apiErr
+
+			// The token expired between fetching it and using it: the request
+			// was rejected before processing, so refresh and resend once
+			if !tokenRefreshed && isTokenRejected(apiErr) {
+				tokenRefreshed = true
+				c.logger.DebugContext(ctx, "mpesa access token rejected, refreshing",
+					slog.String("endpoint", endpoint))
+				c.invalidateToken(ctx)
+				if token, err = c.accessToken(ctx); err != nil {
+					return nil, fmt.Errorf("error getting access token: %w", err)
+				}
+				continue
+			}
+
+			retry.StatusCode = resp.StatusCode
+			retry.Header = resp.Header
+			retry.ErrorCode = apiErr.ErrorCode
 		}
 
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return respBody, nil
+		retry.Elapsed = time.Since(start)
+		wait, ok := policy.Next(retry)
+		if !ok {
+			return nil, fmt.Errorf("request failed after %d retries: %w", attempt-1, lastErr)
+		}
+		c.logger.DebugContext(ctx, "mpesa retrying request",
+			slog.String("endpoint", endpoint),
+			slog.Int("attempt", call.attempts),
+			slog.Duration("wait", wait))
+		c.retried(ctx, call)
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
 		}
+		attempt++
+	}
+}
 
-		// Handle error responses
-		var errorResp models.CommonResponse
-		if err := json.Unmarshal(respBody, &errorResp); err != nil {
-			lastErr = fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(respBody))
-		} else {
-			lastErr = fmt.Errorf("API error: %s - %s", errorResp.ErrorCode, errorResp.ErrorMessage)
+// logAttempt logs the outcome of a single attempt with secrets redacted
+func (c *Client) logAttempt(ctx context.Context, req *http.Request, attempt int, latency time.Duration, resp *http.Response, body []byte, err error) {
+	if !c.debugEnabled(ctx) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("endpoint", req.URL.Path),
+		slog.Int("attempt", attempt),
+		slog.Duration("latency", latency),
+		slog.Any("headers", redactHeader(req.Header)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		c.logger.LogAttrs(ctx, slog.LevelDebug, "mpesa request failed", attrs...)
+		return
+	}
+
+	attrs = append(attrs,
+		slog.Int("status", resp.StatusCode),
+		slog.String("body", redactJSON(body)))
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "mpesa response", attrs...)
+}
+
+// newRequest builds a signed API request with its own reader over body
+func (c *Client) newRequest(ctx context.Context, method, endpoint, token string, body []byte) (*http.Request, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set common headers
+	c.setHeaders(req)
+	for key, values := range requestHeaders(ctx) {
+		for _, value := range values {
+			req.Header.Add(key, value)
 		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return req, nil
+}
+
+// setHeaders adds the custom headers and User-Agent configured on the client
+func (c *Client) setHeaders(req *http.Request) {
+	for key, values := range c.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+}
+
+// send performs a single attempt and closes the response body before returning
+func (c *Client) send(req *http.Request) (*http.Response, []byte, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
 
-		if i < c.config.RetryCount {
-			time.Sleep(c.config.RetryWaitTime)
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	return resp, respBody, nil
+}
+
+// invalidateToken forces the next GetToken call to fetch a new token. A
+// failure to clear a shared store is only logged: the refresh that follows
+// fails on its own if the store is unusable.
+func (c *Client) invalidateToken(ctx context.Context) {
+	switch tokens := c.tokens.(type) {
+	case interface{ Invalidate(context.Context) error }:
+		if err := tokens.Invalidate(ctx); err != nil {
+			c.logger.WarnContext(ctx, "mpesa failed to invalidate shared access token",
+				slog.String("error", err.Error()))
 		}
+	case interface{ Invalidate() }:
+		tokens.Invalidate()
 	}
+}
+
+// isTokenRejected reports whether the API refused the access token
+func isTokenRejected(err *APIError) bool {
+	return err.StatusCode == http.StatusUnauthorized || errors.Is(err, ErrInvalidAccessToken)
+}
 
-	return nil, fmt.Errorf("request failed after %d retries: %w", c.config.RetryCount, lastErr)
+// retryPolicy returns the configured policy or one built from RetryCount and RetryWaitTime
+func (c *Client) retryPolicy() config.RetryPolicy {
+	if c.config.RetryPolicy != nil {
+		return c.config.RetryPolicy
+	}
+	return config.NewExponentialBackoff(c.config.RetryCount, c.config.RetryWaitTime)
+}
+
+type retrySafeKey struct{}
+
+// WithRetrySafe marks requests made with ctx as safe to retry even when their
+// method is not idempotent, e.g. a payment POST carrying a unique reference
+func WithRetrySafe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retrySafeKey{}, true)
+}
+
+func isRetrySafe(ctx context.Context) bool {
+	safe, _ := ctx.Value(retrySafeKey{}).(bool)
+	return safe
+}
+
+// isIdempotent reports whether requests with method can be sent more than once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep waits for d or until ctx is done, whichever comes first
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
@@ -0,0 +1,322 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/auth"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/config"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/token/generate", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"test_token","token_type":"Bearer","expires_in":"3599"}`))
+	})
+	mux.HandleFunc("/", handler)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestConfig(baseURL string) *config.Config {
+	return &config.Config{
+		ConsumerKey:    "key",
+		ConsumerSecret: "secret",
+		BaseURL:        baseURL,
+		Timeout:        time.Second * 5,
+		RetryCount:     2,
+		RetryWaitTime:  time.Millisecond * 10,
+	}
+}
+
+func TestDoRequestContextCancelledDuringRetryWait(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	cfg := newTestConfig(server.URL)
+	cfg.RetryWaitTime = time.Second * 10
+	c := NewClient(cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.DoRequestContext(ctx, http.MethodGet, "/test", nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestDoRequestContextAlreadyCancelled(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	})
+
+	c := NewClient(newTestConfig(server.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := c.DoRequestContext(ctx, http.MethodGet, "/test", nil)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestGetTokenAuthError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"resultCode":"999991","resultDesc":"Invalid client id passed"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(newTestConfig(server.URL))
+	err := c.GetToken()
+
+	assert.EqualError(t, err, "invalid client ID: Invalid client id passed")
+	assert.True(t, errors.Is(err, ErrInvalidClientID))
+	assert.False(t, errors.Is(err, ErrInvalidGrantType))
+
+	var authErr *AuthError
+	assert.True(t, errors.As(err, &authErr))
+	assert.Equal(t, http.StatusBadRequest, authErr.StatusCode)
+	assert.Equal(t, "999991", authErr.ResultCode)
+
+	_, err = c.DoRequest(http.MethodGet, "/test", nil)
+	assert.True(t, errors.Is(err, ErrInvalidClientID))
+}
+
+func TestDoRequestAPIError(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"requestId":"1234-5678","errorCode":"500.003.02","errorMessage":"System is busy"}`))
+	})
+
+	c := NewClient(newTestConfig(server.URL))
+	_, err := c.DoRequest(http.MethodGet, "/test", nil)
+
+	assert.True(t, errors.Is(err, ErrSystemBusy))
+	assert.False(t, errors.Is(err, ErrQuotaViolation))
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, "1234-5678", apiErr.RequestID)
+	assert.Equal(t, "System is busy", apiErr.ErrorMessage)
+	assert.True(t, apiErr.Temporary())
+}
+
+func TestDoRequestRetryClassification(t *testing.T) {
+	tests := []struct {
+		name             string
+		ctx              context.Context
+		method           string
+		status           int
+		expectedAttempts int
+	}{
+		{"POST server error is not retried", context.Background(), http.MethodPost, http.StatusInternalServerError, 1},
+		{"POST marked safe is retried", WithRetrySafe(context.Background()), http.MethodPost, http.StatusInternalServerError, 3},
+		{"POST throttled is retried", context.Background(), http.MethodPost, http.StatusTooManyRequests, 3},
+		{"GET server error is retried", context.Background(), http.MethodGet, http.StatusBadGateway, 3},
+		{"GET bad request is not retried", context.Background(), http.MethodGet, http.StatusBadRequest, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.WriteHeader(tt.status)
+			})
+
+			c := NewClient(newTestConfig(server.URL))
+			_, err := c.DoRequestContext(tt.ctx, tt.method, "/test", nil)
+
+			var apiErr *APIError
+			assert.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, tt.expectedAttempts, attempts)
+		})
+	}
+}
+
+func TestDoRequestResendsBodyOnRetry(t *testing.T) {
+	var bodies []string
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ResponseCode":"0"}`))
+	})
+
+	c := NewClient(newTestConfig(server.URL))
+	resp, err := c.DoRequestContext(WithRetrySafe(context.Background()), http.MethodPost, "/test", map[string]string{"Amount": "10"})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"ResponseCode":"0"}`, string(resp))
+	assert.Equal(t, []string{`{"Amount":"10"}`, `{"Amount":"10"}`, `{"Amount":"10"}`}, bodies)
+}
+
+func TestDoRequestRefreshesRejectedToken(t *testing.T) {
+	tokens := 0
+	var authHeaders []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/token/generate", func(w http.ResponseWriter, r *http.Request) {
+		tokens++
+		fmt.Fprintf(w, `{"access_token":"token_%d","token_type":"Bearer","expires_in":"3599"}`, tokens)
+	})
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") == "Bearer token_1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errorCode":"404.001.03","errorMessage":"Invalid Access Token"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := NewClient(newTestConfig(server.URL))
+	_, err := c.DoRequest(http.MethodPost, "/test", map[string]string{"Amount": "10"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tokens)
+	assert.Equal(t, []string{"Bearer token_1", "Bearer token_2"}, authHeaders)
+}
+
+func TestDoRequestCountsResendAfterTokenRefresh(t *testing.T) {
+	tokens := 0
+	requests := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/token/generate", func(w http.ResponseWriter, r *http.Request) {
+		tokens++
+		fmt.Fprintf(w, `{"access_token":"token_%d","token_type":"Bearer","expires_in":"3599"}`, tokens)
+	})
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") == "Bearer token_1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	recorder := telemetry.NewRecorder()
+	c := NewClient(newTestConfig(server.URL), WithTracer(recorder))
+	_, err := c.DoRequest(http.MethodGet, "/test", nil)
+
+	// The resend does not use up a retry, but it is counted as an attempt
+	assert.EqualError(t, err, "request failed after 2 retries: HTTP 503: ")
+	assert.Equal(t, 4, requests)
+
+	spans := recorder.Spans()
+	last := spans[len(spans)-1]
+	assert.Equal(t, 4, last.Attributes[AttrAttempt])
+}
+
+func TestDoRequestConcurrentTokenFetch(t *testing.T) {
+	var tokens int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/token/generate", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokens, 1)
+		time.Sleep(time.Millisecond * 20)
+		w.Write([]byte(`{"access_token":"test_token","token_type":"Bearer","expires_in":"3599"}`))
+	})
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := NewClient(newTestConfig(server.URL))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.DoRequest(http.MethodPost, "/test", map[string]string{"Amount": "10"})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&tokens))
+}
+
+func TestClientsShareTokenStore(t *testing.T) {
+	var tokens int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/token/generate", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokens, 1)
+		w.Write([]byte(`{"access_token":"test_token","token_type":"Bearer","expires_in":"3599"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store := auth.NewMemoryTokenStore()
+	for i := 0; i < 3; i++ {
+		cfg := newTestConfig(server.URL)
+		cfg.TokenStore = store
+		assert.NoError(t, NewClient(cfg).GetToken())
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&tokens))
+}
+
+func TestClientTokenLifetime(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"test_token","token_type":"Bearer","expires_in":1200}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(newTestConfig(server.URL))
+	assert.Nil(t, c.Token())
+
+	assert.NoError(t, c.GetToken())
+	token := c.Token()
+	assert.Equal(t, "test_token", token.AccessToken)
+	assert.InDelta(t, 1200, token.ExpiresIn().Seconds(), 1)
+}
+
+func TestAuthServiceSharesClientToken(t *testing.T) {
+	var tokens int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokens, 1)
+		assert.NotContains(t, r.Header.Get("Authorization"), "Bearer")
+		w.Write([]byte(`{"access_token":"test_token","token_type":"Bearer","expires_in":"3599"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(newTestConfig(server.URL))
+	service := auth.NewAuthService(c)
+
+	token, err := service.GetToken()
+	assert.NoError(t, err)
+	assert.NoError(t, c.GetToken())
+
+	assert.Equal(t, c.Token().AccessToken, token)
+	assert.Same(t, c.Token(), service.Current())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&tokens))
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+)
+
+// Known authentication result codes, usable with errors.Is
+var (
+	ErrInvalidClientID            = &AuthError{ResultCode: "999991", ResultDesc: "invalid client ID"}
+	ErrInvalidAuthenticationType  = &AuthError{ResultCode: "999996", ResultDesc: "invalid authentication type"}
+	ErrInvalidAuthorizationHeader = &AuthError{ResultCode: "999997", ResultDesc: "invalid authorization header"}
+	ErrInvalidGrantType           = &AuthError{ResultCode: "999998", ResultDesc: "invalid grant type"}
+)
+
+// Known API error codes, usable with errors.Is
+var (
+	ErrBadRequest         = &APIError{ErrorCode: "400.002.02", ErrorMessage: "bad request"}
+	ErrInvalidAccessToken = &APIError{ErrorCode: "404.001.03", ErrorMessage: "invalid access token"}
+	ErrSystemBusy         = &APIError{ErrorCode: "500.003.02", ErrorMessage: "system is busy"}
+	ErrQuotaViolation     = &APIError{ErrorCode: "500.003.03", ErrorMessage: "quota violation"}
+)
+
+// authErrorMessages holds the message prefix used for known authentication result codes
+var authErrorMessages = map[string]string{
+	ErrInvalidClientID.ResultCode:            ErrInvalidClientID.ResultDesc,
+	ErrInvalidAuthenticationType.ResultCode:  ErrInvalidAuthenticationType.ResultDesc,
+	ErrInvalidAuthorizationHeader.ResultCode: ErrInvalidAuthorizationHeader.ResultDesc,
+	ErrInvalidGrantType.ResultCode:           ErrInvalidGrantType.ResultDesc,
+}
+
+// AuthError represents a failed token request
+type AuthError struct {
+	StatusCode int
+	ResultCode string
+	ResultDesc string
+	Body       []byte
+}
+
+func (e *AuthError) Error() string {
+	if e.ResultCode == "" {
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, string(e.Body))
+	}
+	if msg, ok := authErrorMessages[e.ResultCode]; ok {
+		return fmt.Sprintf("%s: %s", msg, e.ResultDesc)
+	}
+	return fmt.Sprintf("authentication error: %s - %s", e.ResultCode, e.ResultDesc)
+}
+
+// Is reports whether target is an AuthError with the same result code
+func (e *AuthError) Is(target error) bool {
+	t, ok := target.(*AuthError)
+	return ok && t.ResultCode != "" && t.ResultCode == e.ResultCode
+}
+
+// APIError represents an error response from the M-PESA API
+type APIError struct {
+	StatusCode   int
+	RequestID    string
+	ErrorCode    string
+	ErrorMessage string
+	Body         []byte
+}
+
+func (e *APIError) Error() string {
+	if e.ErrorCode == "" {
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, string(e.Body))
+	}
+	return fmt.Sprintf("API error: %s - %s", e.ErrorCode, e.ErrorMessage)
+}
+
+// Is reports whether target is an APIError with the same error code
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	return ok && t.ErrorCode != "" && t.ErrorCode == e.ErrorCode
+}
+
+// Temporary reports whether the request may succeed if it is retried
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// newAuthError builds an AuthError from a failed token response
+func newAuthError(statusCode int, body []byte) *AuthError {
+	authErr := &AuthError{
+		StatusCode: statusCode,
+		Body:       body,
+	}
+
+	var errorResp struct {
+		ResultCode string `json:"resultCode"`
+		ResultDesc string `json:"resultDesc"`
+	}
+	if err := json.Unmarshal(body, &errorResp); err == nil {
+		authErr.ResultCode = errorResp.ResultCode
+		authErr.ResultDesc = errorResp.ResultDesc
+	}
+
+	return authErr
+}
+
+// newAPIError builds an APIError from a failed API response
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Body:       body,
+	}
+
+	var errorResp models.CommonResponse
+	if err := json.Unmarshal(body, &errorResp); err == nil {
+		apiErr.RequestID = errorResp.RequestID
+		apiErr.ErrorCode = errorResp.ErrorCode
+		apiErr.ErrorMessage = errorResp.ErrorMessage
+	}
+
+	return apiErr
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
+)
+
+// Metric names recorded through the Meter set with WithMeter
+const (
+	// MetricRequests counts API calls by operation and final status code
+	MetricRequests = "mpesa.client.requests"
+	// MetricRequestDuration records the latency of API calls in seconds,
+	// including retries and waits between them
+	MetricRequestDuration = "mpesa.client.request.duration"
+	// MetricRetries counts retried attempts by operation
+	MetricRetries = "mpesa.client.retries"
+	// MetricErrors counts failed API calls by operation and M-PESA error code
+	MetricErrors = "mpesa.client.errors"
+)
+
+// Attribute keys set on spans and measurements
+const (
+	AttrOperation  = "mpesa.operation"
+	AttrEndpoint   = "mpesa.endpoint"
+	AttrMethod     = "http.request.method"
+	AttrStatusCode = "http.response.status_code"
+	AttrAttempt    = "mpesa.attempt"
+	AttrResultCode = "mpesa.result_code"
+	AttrErrorCode  = "mpesa.error_code"
+)
+
+// WithTracer sets the Tracer that receives a span for every API call
+func WithTracer(tracer telemetry.Tracer) ClientOption {
+	return func(c *Client) {
+		c.tracer = tracer
+	}
+}
+
+// WithMeter sets the Meter that receives request, retry and error metrics
+func WithMeter(meter telemetry.Meter) ClientOption {
+	return func(c *Client) {
+		c.meter = meter
+	}
+}
+
+// operationName returns the operation set on ctx with telemetry.WithOperation,
+// or the path of endpoint when the caller did not name one
+func operationName(ctx context.Context, endpoint string) string {
+	if operation := telemetry.Operation(ctx); operation != "" {
+		return operation
+	}
+	path, _, _ := strings.Cut(endpoint, "?")
+	return path
+}
+
+// callInfo collects what an instrumented call learns while it runs
+type callInfo struct {
+	operation  string
+	endpoint   string
+	attempts   int
+	statusCode int
+}
+
+// startCall starts the span of an API call
+func (c *Client) startCall(ctx context.Context, method, endpoint string) (context.Context, telemetry.Span, *callInfo) {
+	path, _, _ := strings.Cut(endpoint, "?")
+	call := &callInfo{
+		operation: operationName(ctx, endpoint),
+		endpoint:  path,
+	}
+	ctx, span := c.tracer.Start(ctx, call.operation,
+		telemetry.String(AttrOperation, call.operation),
+		telemetry.String(AttrEndpoint, path),
+		telemetry.String(AttrMethod, method))
+	return ctx, span, call
+}
+
+// retried records that an attempt of call is about to be retried
+func (c *Client) retried(ctx context.Context, call *callInfo) {
+	c.meter.Add(ctx, MetricRetries, 1, telemetry.String(AttrOperation, call.operation))
+}
+
+// endCall finishes the span of an API call and records its metrics
+func (c *Client) endCall(ctx context.Context, span telemetry.Span, call *callInfo, start time.Time, body []byte, err error) {
+	defer span.End()
+
+	span.SetAttributes(telemetry.Int(AttrAttempt, call.attempts))
+	if call.statusCode != 0 {
+		span.SetAttributes(telemetry.Int(AttrStatusCode, call.statusCode))
+	}
+
+	operation := telemetry.String(AttrOperation, call.operation)
+	c.meter.Record(ctx, MetricRequestDuration, time.Since(start).Seconds(), operation)
+	c.meter.Add(ctx, MetricRequests, 1, operation, telemetry.Int(AttrStatusCode, call.statusCode))
+
+	if err != nil {
+		code := errorCode(err)
+		span.RecordError(err)
+		span.SetAttributes(telemetry.String(AttrErrorCode, code))
+		c.meter.Add(ctx, MetricErrors, 1, operation, telemetry.String(AttrErrorCode, code))
+		return
+	}
+	if code := resultCode(body); code != "" {
+		span.SetAttributes(telemetry.String(AttrResultCode, code))
+	}
+}
+
+// errorCode returns the M-PESA code of err, or a coarse class when there is none
+func errorCode(err error) string {
+	var apiErr *APIError
+	var authErr *AuthError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.ErrorCode != "":
+		return apiErr.ErrorCode
+	case errors.As(err, &apiErr):
+		return fmt.Sprintf("http.%d", apiErr.StatusCode)
+	case errors.As(err, &authErr) && authErr.ResultCode != "":
+		return authErr.ResultCode
+	case errors.As(err, &authErr):
+		return fmt.Sprintf("http.%d", authErr.StatusCode)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "context"
+	default:
+		return "transport"
+	}
+}
+
+// resultCode reads the ResponseCode or ResultCode of a successful response
+func resultCode(body []byte) string {
+	var fields map[string]interface{}
+	if json.Unmarshal(body, &fields) != nil {
+		return ""
+	}
+	for _, key := range []string{"ResponseCode", "ResultCode", "responseCode", "resultCode"} {
+		if value, ok := fields[key]; ok && value != nil {
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/stkpush"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentationRecordsSpansAndMetrics(t *testing.T) {
+	attempts := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"MerchantRequestID":"1","CheckoutRequestID":"ws_CO_1","ResponseCode":"0"}`))
+	})
+
+	recorder := telemetry.NewRecorder()
+	c := NewClient(newTestConfig(server.URL), WithTracer(recorder), WithMeter(recorder))
+
+	service := stkpush.NewSTKPushService(c)
+	_, err := service.InitiateSTKPush(&stkpush.STKPushRequest{BusinessShortCode: "554433", Password: "123"})
+	require.NoError(t, err)
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "token.generate", spans[0].Name)
+	assert.Equal(t, "stkpush.initiate", spans[0].Parent)
+	assert.Equal(t, http.StatusOK, spans[0].Attributes[AttrStatusCode])
+
+	assert.Equal(t, "stkpush.initiate", spans[1].Name)
+	assert.Equal(t, "/mpesa/stkpush/v3/processrequest", spans[1].Attributes[AttrEndpoint])
+	assert.Equal(t, 2, spans[1].Attributes[AttrAttempt])
+	assert.Equal(t, "0", spans[1].Attributes[AttrResultCode])
+	assert.Empty(t, spans[1].Errors)
+
+	operation := telemetry.String(AttrOperation, "stkpush.initiate")
+	assert.Equal(t, int64(1), recorder.Counter(MetricRetries, operation))
+	assert.Equal(t, int64(1), recorder.Counter(MetricRequests, operation, telemetry.Int(AttrStatusCode, http.StatusOK)))
+	assert.Equal(t, int64(0), recorder.Counter(MetricErrors, operation))
+	assert.Len(t, recorder.Histogram(MetricRequestDuration, operation), 1)
+}
+
+func TestInstrumentationRecordsErrorCodes(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"requestId":"1","errorCode":"400.002.02","errorMessage":"Bad Request"}`))
+	})
+
+	recorder := telemetry.NewRecorder()
+	c := NewClient(newTestConfig(server.URL), WithTracer(recorder), WithMeter(recorder))
+
+	ctx := telemetry.WithOperation(context.Background(), "c2b.register_url")
+	_, err := c.DoRequestContext(ctx, http.MethodPost, "/v1/c2b-register-url/register", nil)
+	require.Error(t, err)
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "c2b.register_url", spans[1].Name)
+	assert.Equal(t, "400.002.02", spans[1].Attributes[AttrErrorCode])
+	assert.Len(t, spans[1].Errors, 1)
+
+	operation := telemetry.String(AttrOperation, "c2b.register_url")
+	assert.Equal(t, int64(1), recorder.Counter(MetricErrors, operation, telemetry.String(AttrErrorCode, "400.002.02")))
+	assert.Equal(t, int64(0), recorder.Counter(MetricRetries, operation))
+}
+
+func TestOperationName(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "b2b.payment", operationName(telemetry.WithOperation(ctx, "b2b.payment"), "/mpesa/b2b/v1/paymentrequest"))
+	assert.Equal(t, "/v1/token/generate", operationName(ctx, "/v1/token/generate?grant_type=client_credentials"))
+}
+
+func TestInstrumentationNamesUnnamedCallsByPath(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	})
+
+	recorder := telemetry.NewRecorder()
+	c := NewClient(newTestConfig(server.URL), WithTracer(recorder))
+
+	_, err := c.DoRequest(http.MethodPost, "/custom/v1/endpoint", nil)
+	require.NoError(t, err)
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "token.generate", spans[0].Name)
+	assert.Equal(t, "/custom/v1/endpoint", spans[0].Parent)
+	assert.Equal(t, "/custom/v1/endpoint", spans[1].Name)
+}
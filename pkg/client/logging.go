@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// secretFields are JSON fields whose values are never logged
+var secretFields = map[string]bool{
+	"securitycredential": true,
+	"password":           true,
+	"initiatorpassword":  true,
+	"secretkey":          true,
+	"passkey":            true,
+	"access_token":       true,
+}
+
+// phoneFields are JSON fields holding an MSISDN, which is logged masked
+var phoneFields = map[string]bool{
+	"phonenumber":     true,
+	"msisdn":          true,
+	"customermsisdn":  true,
+	"partya":          true,
+	"partyb":          true,
+	"requester":       true,
+	"nominatednumber": true,
+	"sender":          true,
+}
+
+// discardHandler is the slog.Handler used when no logger is configured
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// debugEnabled reports whether debug logs are wanted, so that redaction is
+// skipped when nobody reads the result
+func (c *Client) debugEnabled(ctx context.Context) bool {
+	return c.logger.Enabled(ctx, slog.LevelDebug)
+}
+
+// redactHeader returns a copy of h with credentials removed
+func redactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	if auth := out.Get("Authorization"); auth != "" {
+		scheme, _, found := strings.Cut(auth, " ")
+		if found {
+			out.Set("Authorization", scheme+" "+redacted)
+		} else {
+			out.Set("Authorization", redacted)
+		}
+	}
+	return out
+}
+
+// redactJSON returns body with secret fields replaced and phone numbers
+// masked. Bodies that are not JSON are dropped entirely rather than risk
+// leaking their contents.
+func redactJSON(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var v interface{}
+	decoder := json.NewDecoder(strings.NewReader(string(body)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return redacted
+	}
+
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return redacted
+	}
+	return string(out)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			switch name := strings.ToLower(key); {
+			case secretFields[name]:
+				v[key] = redacted
+			case phoneFields[name]:
+				v[key] = maskValue(value)
+			default:
+				v[key] = redactValue(value)
+			}
+		}
+		// Callback metadata and result parameters are name/value pairs
+		if name, ok := pairName(v); ok {
+			if value, ok := v["Value"]; ok {
+				switch {
+				case secretFields[name]:
+					v["Value"] = redacted
+				case phoneFields[name]:
+					v["Value"] = maskValue(value)
+				}
+			}
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// pairName returns the lowercased Name or Key of a name/value pair
+func pairName(v map[string]interface{}) (string, bool) {
+	for _, key := range []string{"Name", "Key"} {
+		if name, ok := v[key].(string); ok {
+			return strings.ToLower(name), true
+		}
+	}
+	return "", false
+}
+
+func maskValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return maskMSISDN(v)
+	case json.Number:
+		return maskMSISDN(v.String())
+	default:
+		return v
+	}
+}
+
+// maskMSISDN hides the middle digits of a phone number, keeping the country
+// and operator prefix and the last three digits. Values too short to be a
+// phone number, such as shortcodes, are returned unchanged.
+func maskMSISDN(msisdn string) string {
+	if len(msisdn) < 9 {
+		return msisdn
+	}
+	return msisdn[:4] + strings.Repeat("*", len(msisdn)-7) + msisdn[len(msisdn)-3:]
+}
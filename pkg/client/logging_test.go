@@ -0,0 +1,97 @@
+package client
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			"secrets",
+			`{"SecurityCredential":"abc","Password":"def","SecretKey":"ghi","Amount":10}`,
+			`{"Amount":10,"Password":"[REDACTED]","SecretKey":"[REDACTED]","SecurityCredential":"[REDACTED]"}`,
+		},
+		{
+			"phone numbers",
+			`{"PhoneNumber":"251700404789","PartyA":251700404789,"PartyB":"174379"}`,
+			`{"PartyA":"2517*****789","PartyB":"174379","PhoneNumber":"2517*****789"}`,
+		},
+		{
+			"b2b requester",
+			`{"CommandID":"BusinessPayBill","PartyA":"600979","PartyB":"600000","Requester":"251700404789"}`,
+			`{"CommandID":"BusinessPayBill","PartyA":"600979","PartyB":"600000","Requester":"2517*****789"}`,
+		},
+		{
+			"pull transactions",
+			`{"ShortCode":"600000","NominatedNumber":"251700404789","Response":[[{"transactionId":"A","msisdn":"251700404789","sender":"251700404789"}]]}`,
+			`{"NominatedNumber":"2517*****789","Response":[[{"msisdn":"2517*****789","sender":"2517*****789","transactionId":"A"}]],"ShortCode":"600000"}`,
+		},
+		{
+			"callback metadata",
+			`{"Item":[{"Name":"Amount","Value":10},{"Name":"PhoneNumber","Value":251700404789}]}`,
+			`{"Item":[{"Name":"Amount","Value":10},{"Name":"PhoneNumber","Value":"2517*****789"}]}`,
+		},
+		{"not JSON", `access_token=abc`, `[REDACTED]`},
+		{"empty", ``, ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, redactJSON([]byte(tt.body)))
+		})
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer test_token")
+	h.Set("User-Agent", "test")
+
+	redactedHeader := redactHeader(h)
+	assert.Equal(t, "Bearer [REDACTED]", redactedHeader.Get("Authorization"))
+	assert.Equal(t, "test", redactedHeader.Get("User-Agent"))
+	assert.Equal(t, "Bearer test_token", h.Get("Authorization"))
+}
+
+func TestDoRequestLogsRedacted(t *testing.T) {
+	attempts := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ResponseCode":"0"}`))
+	})
+
+	var logs bytes.Buffer
+	cfg := newTestConfig(server.URL)
+	cfg.Logger = slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := NewClient(cfg)
+
+	_, err := c.DoRequest(http.MethodGet, "/test", map[string]string{
+		"SecurityCredential": "credential_value",
+		"PhoneNumber":        "251700404789",
+	})
+	assert.NoError(t, err)
+
+	output := logs.String()
+	assert.Contains(t, output, "msg=\"mpesa token response\"")
+	assert.Contains(t, output, "status=503")
+	assert.Contains(t, output, "attempt=2")
+	assert.Contains(t, output, "status=200")
+	assert.Contains(t, output, "latency=")
+	assert.Contains(t, output, "2517*****789")
+	assert.NotContains(t, output, "test_token")
+	assert.NotContains(t, output, "credential_value")
+	assert.NotContains(t, output, "251700404789")
+}
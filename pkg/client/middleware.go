@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Doer performs an API call; Client and the services built on it all go through one
+type Doer interface {
+	DoRequestContext(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error)
+}
+
+// DoerFunc adapts an ordinary function to a Doer
+type DoerFunc func(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error)
+
+// DoRequestContext calls f
+func (f DoerFunc) DoRequestContext(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+	return f(ctx, method, endpoint, body)
+}
+
+// Middleware wraps a Doer to add behaviour such as logging or metrics around every call
+type Middleware func(next Doer) Doer
+
+// WithMiddleware appends middleware to the client; the first one given is the outermost
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// chain wraps doer in middleware so that middleware[0] runs first
+func chain(doer Doer, middleware []Middleware) Doer {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		doer = middleware[i](doer)
+	}
+	return doer
+}
+
+type requestHeadersKey struct{}
+
+// WithRequestHeader returns a context that makes the client add the header
+// to the HTTP request, letting middleware stamp headers on individual calls
+func WithRequestHeader(ctx context.Context, key, value string) context.Context {
+	headers := requestHeaders(ctx).Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	headers.Add(key, value)
+	return context.WithValue(ctx, requestHeadersKey{}, headers)
+}
+
+func requestHeaders(ctx context.Context) http.Header {
+	headers, _ := ctx.Value(requestHeadersKey{}).(http.Header)
+	return headers
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/stkpush"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareChain(t *testing.T) {
+	var requestID string
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requestID = r.Header.Get("X-Request-ID")
+		w.Write([]byte(`{"MerchantRequestID":"1","CheckoutRequestID":"ws_CO_1","ResponseCode":"0"}`))
+	})
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+				calls = append(calls, name+" before "+endpoint)
+				resp, err := next.DoRequestContext(ctx, method, endpoint, body)
+				calls = append(calls, name+" after")
+				return resp, err
+			})
+		}
+	}
+	stampRequestID := func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+			return next.DoRequestContext(WithRequestHeader(ctx, "X-Request-ID", "req-42"), method, endpoint, body)
+		})
+	}
+
+	c := NewClient(newTestConfig(server.URL), WithMiddleware(record("outer"), record("inner")), WithMiddleware(stampRequestID))
+
+	// Services built on the client go through the chain too
+	service := stkpush.NewSTKPushService(c)
+	resp, err := service.InitiateSTKPush(&stkpush.STKPushRequest{BusinessShortCode: "554433", Password: "123"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ws_CO_1", resp.CheckoutRequestID)
+	assert.Equal(t, "req-42", requestID)
+	assert.Equal(t, []string{
+		"outer before /mpesa/stkpush/v3/processrequest",
+		"inner before /mpesa/stkpush/v3/processrequest",
+		"inner after",
+		"outer after",
+	}, calls)
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	called := false
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	errBlocked := errors.New("blocked")
+	block := func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+			return nil, errBlocked
+		})
+	}
+
+	c := NewClient(newTestConfig(server.URL), WithMiddleware(block))
+	_, err := c.DoRequest(http.MethodPost, "/test", nil)
+
+	assert.True(t, errors.Is(err, errBlocked))
+	assert.False(t, called)
+}
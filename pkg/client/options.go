@@ -0,0 +1,57 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DefaultUserAgent is sent with every request unless WithUserAgent overrides it
+const DefaultUserAgent = "mpesa-sdk-go"
+
+// ClientOption defines a function type for client options
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the http.Client used for all requests; its Timeout
+// takes the place of config.Config.Timeout
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport sets the RoundTripper used for all requests, e.g. for TLS
+// settings, proxies or instrumentation
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		// Copy so an http.Client passed to WithHTTPClient is not modified
+		httpClient := *c.httpClient
+		httpClient.Transport = transport
+		c.httpClient = &httpClient
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithBaseURL overrides config.Config.BaseURL, e.g. to route through a gateway
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHeaders adds headers to every request; they cannot override the
+// Authorization and Content-Type headers set by the client
+func WithHeaders(headers http.Header) ClientOption {
+	return func(c *Client) {
+		for key, values := range headers {
+			for _, value := range values {
+				c.headers.Add(key, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requests = append(rt.requests, req)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClientOptions(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	})
+
+	transport := &recordingTransport{}
+	httpClient := &http.Client{}
+	c := NewClient(
+		newTestConfig("http://unused.invalid"),
+		WithHTTPClient(httpClient),
+		WithTransport(transport),
+		WithBaseURL(server.URL+"/"),
+		WithUserAgent("shop/1.0"),
+		WithHeaders(http.Header{"X-Tenant": []string{"retail"}, "Authorization": []string{"ignored"}}),
+	)
+
+	_, err := c.DoRequest(http.MethodPost, "/test", map[string]string{"Amount": "10"})
+	assert.NoError(t, err)
+
+	// The caller's http.Client is left untouched
+	assert.Nil(t, httpClient.Transport)
+
+	assert.Len(t, transport.requests, 2)
+	for _, req := range transport.requests {
+		assert.Equal(t, "shop/1.0", req.Header.Get("User-Agent"))
+		assert.Equal(t, "retail", req.Header.Get("X-Tenant"))
+		assert.Len(t, req.Header.Values("Authorization"), 1)
+	}
+	assert.Equal(t, "/v1/token/generate", transport.requests[0].URL.Path)
+	assert.Equal(t, "/test", transport.requests[1].URL.Path)
+	assert.Equal(t, "Bearer test_token", transport.requests[1].Header.Get("Authorization"))
+}
+
+func TestDefaultUserAgent(t *testing.T) {
+	var userAgent string
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.Write([]byte(`{}`))
+	})
+
+	c := NewClient(newTestConfig(server.URL))
+	_, err := c.DoRequest(http.MethodGet, "/test", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultUserAgent, userAgent)
+}
@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
 	"time"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/auth"
 )
 
 // Environment represents the M-PESA API environment
@@ -22,6 +25,30 @@ type Config struct {
 	Timeout        time.Duration
 	RetryCount     int
 	RetryWaitTime  time.Duration
+	// RetryPolicy overrides the exponential backoff built from RetryCount and RetryWaitTime
+	RetryPolicy RetryPolicy
+	// TokenStore shares access tokens between replicas; nil keeps them in the client only
+	TokenStore auth.TokenStore
+	// TokenRefreshSkew is how long before expiry a token is refreshed
+	TokenRefreshSkew time.Duration
+	// OnTokenExpired is called with each token that is about to be replaced
+	OnTokenExpired func(expired *auth.Token)
+	// Passkeys maps a Lipa Na M-PESA shortcode to its passkey
+	Passkeys map[string]string
+	// Certificate holds the PEM or DER encoded M-PESA public certificate used
+	// to encrypt initiator passwords; it takes precedence over CertificatePath.
+	// The SDK ships no certificates, so supply the one for Environment.
+	Certificate     []byte
+	CertificatePath string
+	// Logger receives debug logs for every request with secrets and phone
+	// numbers redacted; nil disables logging
+	Logger *slog.Logger
+}
+
+// ConfigProvider is implemented by clients that expose their configuration,
+// such as client.Client; services use it to read settings like passkeys
+type ConfigProvider interface {
+	Config() *Config
 }
 
 // ConfigOption defines a function type for configuration options
@@ -46,6 +73,8 @@ func NewConfig(consumerKey, consumerSecret string, env Environment, options ...C
 		Timeout:        time.Second * 5,
 		RetryCount:     2,
 		RetryWaitTime:  time.Second * 5,
+		// Refresh a minute before expiry so tokens do not expire mid-request
+		TokenRefreshSkew: time.Minute,
 	}
 
 	// Apply options
@@ -70,3 +99,62 @@ func WithRetry(count int, waitTime time.Duration) ConfigOption {
 		c.RetryWaitTime = waitTime
 	}
 }
+
+// WithRetryPolicy sets a custom retry policy
+func WithRetryPolicy(policy RetryPolicy) ConfigOption {
+	return func(c *Config) {
+		c.RetryPolicy = policy
+	}
+}
+
+// WithTokenStore sets the store used to share access tokens between replicas
+func WithTokenStore(store auth.TokenStore) ConfigOption {
+	return func(c *Config) {
+		c.TokenStore = store
+	}
+}
+
+// WithTokenRefreshSkew sets how long before expiry a token is refreshed
+func WithTokenRefreshSkew(skew time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.TokenRefreshSkew = skew
+	}
+}
+
+// WithTokenExpiredHook sets a function called with each token that is about to be replaced
+func WithTokenExpiredHook(hook func(expired *auth.Token)) ConfigOption {
+	return func(c *Config) {
+		c.OnTokenExpired = hook
+	}
+}
+
+// WithPasskey sets the Lipa Na M-PESA passkey used to derive STK push passwords for shortCode
+func WithPasskey(shortCode, passkey string) ConfigOption {
+	return func(c *Config) {
+		if c.Passkeys == nil {
+			c.Passkeys = make(map[string]string)
+		}
+		c.Passkeys[shortCode] = passkey
+	}
+}
+
+// WithCertificate sets the PEM or DER encoded M-PESA public certificate
+func WithCertificate(cert []byte) ConfigOption {
+	return func(c *Config) {
+		c.Certificate = cert
+	}
+}
+
+// WithCertificatePath sets the path of the M-PESA public certificate
+func WithCertificatePath(path string) ConfigOption {
+	return func(c *Config) {
+		c.CertificatePath = path
+	}
+}
+
+// WithLogger sets the logger used for request debug logs
+func WithLogger(logger *slog.Logger) ConfigOption {
+	return func(c *Config) {
+		c.Logger = logger
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryAttempt describes a failed attempt that a RetryPolicy classifies
+type RetryAttempt struct {
+	// Attempt is the 1-based number of the attempt that failed
+	Attempt int
+	// Elapsed is the time since the first attempt started
+	Elapsed time.Duration
+	Method  string
+	// Idempotent reports whether the request is safe to send more than once,
+	// either because of its method or because the caller marked it safe
+	Idempotent bool
+	// StatusCode is 0 when no response was received
+	StatusCode int
+	Header     http.Header
+	// ErrorCode is the M-PESA errorCode from the response body, if any
+	ErrorCode string
+	// Err is the transport error when no response was received
+	Err error
+}
+
+// RetryPolicy decides whether a failed request is retried and how long to wait first
+type RetryPolicy interface {
+	Next(attempt RetryAttempt) (time.Duration, bool)
+}
+
+// ExponentialBackoff retries network errors, 429, 5xx and throttling error
+// codes with exponentially growing, jittered waits.
+//
+// Requests that are not idempotent are only retried when M-PESA rejected
+// them before processing: the connection could not be established, the
+// response was 429, or the error code is one of ThrottlingErrorCodes.
+type ExponentialBackoff struct {
+	MaxRetries      int
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	// Jitter randomizes each wait by up to this fraction in either direction
+	Jitter float64
+	// MaxElapsedTime stops retrying once this much time has passed; 0 disables the limit
+	MaxElapsedTime time.Duration
+	// ThrottlingErrorCodes are M-PESA error codes that are always safe to retry
+	ThrottlingErrorCodes []string
+}
+
+// NewExponentialBackoff creates a policy allowing maxRetries retries starting at initialInterval
+func NewExponentialBackoff(maxRetries int, initialInterval time.Duration) *ExponentialBackoff {
+	return &ExponentialBackoff{
+		MaxRetries:      maxRetries,
+		InitialInterval: initialInterval,
+		MaxInterval:     time.Second * 30,
+		Multiplier:      2,
+		Jitter:          0.2,
+		MaxElapsedTime:  time.Minute,
+		ThrottlingErrorCodes: []string{
+			"500.003.02", // System is busy
+			"500.003.03", // Quota violation
+		},
+	}
+}
+
+// Next implements RetryPolicy
+func (b *ExponentialBackoff) Next(a RetryAttempt) (time.Duration, bool) {
+	if a.Attempt > b.MaxRetries || !b.retryable(a) {
+		return 0, false
+	}
+
+	wait := b.backoff(a.Attempt)
+	if retryAfter, ok := parseRetryAfter(a.Header); ok {
+		wait = retryAfter
+	}
+
+	if b.MaxElapsedTime > 0 && a.Elapsed+wait > b.MaxElapsedTime {
+		return 0, false
+	}
+	return wait, true
+}
+
+// retryable classifies the failed attempt
+func (b *ExponentialBackoff) retryable(a RetryAttempt) bool {
+	if a.ErrorCode != "" {
+		for _, code := range b.ThrottlingErrorCodes {
+			if code == a.ErrorCode {
+				return true
+			}
+		}
+	}
+
+	switch {
+	case a.Err != nil:
+		return a.Idempotent || isDialError(a.Err)
+	case a.StatusCode == http.StatusTooManyRequests:
+		return true
+	case a.StatusCode >= 500:
+		return a.Idempotent
+	default:
+		return false
+	}
+}
+
+// backoff returns the jittered wait before retrying after attempt
+func (b *ExponentialBackoff) backoff(attempt int) time.Duration {
+	wait := float64(b.InitialInterval)
+	for i := 1; i < attempt; i++ {
+		wait *= b.Multiplier
+		if b.MaxInterval > 0 && wait >= float64(b.MaxInterval) {
+			wait = float64(b.MaxInterval)
+			break
+		}
+	}
+
+	if b.Jitter > 0 {
+		wait += wait * b.Jitter * (2*rand.Float64() - 1)
+	}
+	if b.MaxInterval > 0 && wait > float64(b.MaxInterval) {
+		wait = float64(b.MaxInterval)
+	}
+	return time.Duration(wait)
+}
+
+// isDialError reports whether err happened before the request was sent
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
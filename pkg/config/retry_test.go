@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExponentialBackoffClassification(t *testing.T) {
+	policy := NewExponentialBackoff(3, time.Second)
+	policy.Jitter = 0
+
+	dialErr := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}
+
+	tests := []struct {
+		name          string
+		attempt       RetryAttempt
+		expectedRetry bool
+	}{
+		{"GET network error", RetryAttempt{Attempt: 1, Idempotent: true, Err: readErr}, true},
+		{"POST dial error", RetryAttempt{Attempt: 1, Err: dialErr}, true},
+		{"POST read error", RetryAttempt{Attempt: 1, Err: readErr}, false},
+		{"GET 503", RetryAttempt{Attempt: 1, Idempotent: true, StatusCode: http.StatusServiceUnavailable}, true},
+		{"POST 503", RetryAttempt{Attempt: 1, StatusCode: http.StatusServiceUnavailable}, false},
+		{"POST 429", RetryAttempt{Attempt: 1, StatusCode: http.StatusTooManyRequests}, true},
+		{"POST quota violation", RetryAttempt{Attempt: 1, StatusCode: http.StatusInternalServerError, ErrorCode: "500.003.03"}, true},
+		{"GET 400", RetryAttempt{Attempt: 1, Idempotent: true, StatusCode: http.StatusBadRequest}, false},
+		{"retries exhausted", RetryAttempt{Attempt: 4, Idempotent: true, StatusCode: http.StatusServiceUnavailable}, false},
+		{"max elapsed time", RetryAttempt{Attempt: 1, Idempotent: true, Elapsed: time.Minute, StatusCode: http.StatusServiceUnavailable}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := policy.Next(tt.attempt)
+			assert.Equal(t, tt.expectedRetry, ok)
+		})
+	}
+}
+
+func TestExponentialBackoffWait(t *testing.T) {
+	policy := NewExponentialBackoff(10, time.Second)
+	policy.Jitter = 0
+	policy.MaxInterval = time.Second * 5
+	policy.MaxElapsedTime = 0
+
+	for attempt, expected := range map[int]time.Duration{
+		1: time.Second,
+		2: time.Second * 2,
+		3: time.Second * 4,
+		4: time.Second * 5,
+		8: time.Second * 5,
+	} {
+		wait, ok := policy.Next(RetryAttempt{Attempt: attempt, Idempotent: true, StatusCode: http.StatusBadGateway})
+		assert.True(t, ok)
+		assert.Equal(t, expected, wait, "attempt %d", attempt)
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		wait, _ := policy.Next(RetryAttempt{Attempt: 2, Idempotent: true, StatusCode: http.StatusBadGateway})
+		assert.GreaterOrEqual(t, wait, time.Second)
+		assert.LessOrEqual(t, wait, time.Second*3)
+	}
+}
+
+func TestExponentialBackoffRetryAfter(t *testing.T) {
+	policy := NewExponentialBackoff(3, time.Second)
+
+	header := http.Header{}
+	header.Set("Retry-After", "7")
+	wait, ok := policy.Next(RetryAttempt{Attempt: 1, StatusCode: http.StatusTooManyRequests, Header: header})
+	assert.True(t, ok)
+	assert.Equal(t, time.Second*7, wait)
+
+	header.Set("Retry-After", "120")
+	_, ok = policy.Next(RetryAttempt{Attempt: 1, StatusCode: http.StatusTooManyRequests, Header: header})
+	assert.False(t, ok)
+}
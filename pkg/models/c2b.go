@@ -18,6 +18,9 @@ type Initiator struct {
 	Identifier         string `json:"Identifier"`
 	SecurityCredential string `json:"SecurityCredential"`
 	SecretKey          string `json:"SecretKey"`
+
+	// InitiatorPassword is encrypted into SecurityCredential when the latter is empty
+	InitiatorPassword string `json:"-"`
 }
 
 // Party represents a transaction party
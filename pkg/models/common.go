@@ -1,5 +1,12 @@
 package models
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // CommonResponse represents common response fields
 type CommonResponse struct {
 	RequestID    string `json:"requestId,omitempty"`
@@ -9,7 +16,38 @@ type CommonResponse struct {
 
 // AuthResponse represents the authentication response
 type AuthResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   string `json:"expires_in"`
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   ExpiresIn `json:"expires_in"`
+}
+
+// ExpiresIn is a token lifetime in seconds, sent either as a number or as a string
+type ExpiresIn int64
+
+// UnmarshalJSON accepts 3599, "3599" and an empty or missing value
+func (e *ExpiresIn) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		*e = 0
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = strings.TrimSpace(unquoted)
+	}
+	if s == "" {
+		*e = 0
+		return nil
+	}
+
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil || seconds < 0 {
+		return fmt.Errorf("invalid expires_in: %s", data)
+	}
+	*e = ExpiresIn(seconds)
+	return nil
+}
+
+// Duration returns the lifetime as a time.Duration
+func (e ExpiresIn) Duration() time.Duration {
+	return time.Duration(e) * time.Second
 }
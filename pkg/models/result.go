@@ -0,0 +1,160 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ResultCallback represents the payload M-PESA posts to a ResultURL
+type ResultCallback struct {
+	Result Result `json:"Result"`
+}
+
+// Result represents the asynchronous outcome of an initiator request
+type Result struct {
+	ResultType               int              `json:"ResultType"`
+	ResultCode               int              `json:"ResultCode"`
+	ResultDesc               string           `json:"ResultDesc"`
+	OriginatorConversationID string           `json:"OriginatorConversationID"`
+	ConversationID           string           `json:"ConversationID"`
+	TransactionID            string           `json:"TransactionID"`
+	ResultParameters         ResultParameters `json:"ResultParameters"`
+	ReferenceData            ReferenceData    `json:"ReferenceData"`
+}
+
+// ResultParameter represents a key-value pair in the result parameters
+type ResultParameter struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+// ResultParameters holds the parameters attached to a result
+type ResultParameters struct {
+	ResultParameter []ResultParameter `json:"ResultParameter"`
+}
+
+// ReferenceData holds the reference items echoed back in a result
+type ReferenceData struct {
+	ReferenceItem []ReferenceItem `json:"ReferenceItem"`
+}
+
+// ParseResult decodes a result payload posted to a ResultURL
+func ParseResult(r io.Reader) (*Result, error) {
+	var cb ResultCallback
+	if err := json.NewDecoder(r).Decode(&cb); err != nil {
+		return nil, fmt.Errorf("failed to parse result: %w", err)
+	}
+	if cb.Result.ConversationID == "" && cb.Result.OriginatorConversationID == "" {
+		return nil, fmt.Errorf("failed to parse result: missing conversation ID")
+	}
+	return &cb.Result, nil
+}
+
+// Get returns the value of the parameter with the given key
+func (p ResultParameters) Get(key string) (string, bool) {
+	for _, param := range p.ResultParameter {
+		if param.Key == key {
+			return param.Value, true
+		}
+	}
+	return "", false
+}
+
+// Float returns the numeric value of the parameter with the given key, or 0 if it is absent
+func (p ResultParameters) Float(key string) (float64, error) {
+	v, ok := p.Get(key)
+	if !ok || v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return f, nil
+}
+
+// UnmarshalJSON accepts both numeric and string parameter values
+func (p *ResultParameter) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Key   string          `json:"Key"`
+		Value json.RawMessage `json:"Value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	p.Key = raw.Key
+	value, err := StringOrNumber(raw.Value)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", raw.Key, err)
+	}
+	p.Value = value
+	return nil
+}
+
+// UnmarshalJSON accepts a single parameter object as well as an array
+func (p *ResultParameters) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ResultParameter json.RawMessage `json:"ResultParameter"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	return oneOrMany(raw.ResultParameter, &p.ResultParameter)
+}
+
+// UnmarshalJSON accepts a single reference item object as well as an array
+func (d *ReferenceData) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ReferenceItem json.RawMessage `json:"ReferenceItem"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	return oneOrMany(raw.ReferenceItem, &d.ReferenceItem)
+}
+
+// oneOrMany decodes data into out whether it holds a single object or an array
+func oneOrMany[T any](data json.RawMessage, out *[]T) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		*out = nil
+		return nil
+	}
+	if data[0] == '[' {
+		return json.Unmarshal(data, out)
+	}
+
+	var item T
+	if err := json.Unmarshal(data, &item); err != nil {
+		return err
+	}
+	*out = []T{item}
+	return nil
+}
+
+// StringOrNumber returns a JSON string or number as a plain string, keeping
+// numbers exactly as written; null gives "". Any other JSON value is an error.
+func StringOrNumber(data json.RawMessage) (string, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return "", nil
+	}
+
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return "", fmt.Errorf("expected a string or number, got %s", data)
+	}
+	return n.String(), nil
+}
@@ -0,0 +1,260 @@
+package pulltransactions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/internal/transport"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
+)
+
+// DateLayout is the layout of StartDate and EndDate in a query
+const DateLayout = "2006-01-02 15:04:05"
+
+// SuccessCode is the ResponseCode of a successful query
+const SuccessCode = "1000"
+
+// MaxPages is the most pages QueryAll requests before giving up, so that an
+// API that keeps returning transactions cannot make it loop forever
+const MaxPages = 1000
+
+type PullTransactionsService struct {
+	client interface {
+		DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+	}
+}
+
+func NewPullTransactionsService(client interface {
+	DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+}) *PullTransactionsService {
+	return &PullTransactionsService{
+		client: client,
+	}
+}
+
+// RegisterRequest represents the registration of a shortcode for the Pull API
+type RegisterRequest struct {
+	ShortCode   string `json:"ShortCode"`
+	RequestType string `json:"RequestType"`
+	// NominatedNumber is the phone number notified about the registration
+	NominatedNumber string `json:"NominatedNumber"`
+	CallBackURL     string `json:"CallBackURL"`
+}
+
+// RegisterResponse represents the response to a Pull API registration
+type RegisterResponse struct {
+	ResponseRefID       string `json:"ResponseRefID"`
+	ResponseStatus      string `json:"ResponseStatus"`
+	ShortCode           string `json:"ShortCode"`
+	ResponseDescription string `json:"ResponseDescription"`
+}
+
+// QueryRequest represents a query for the transactions of a time window
+type QueryRequest struct {
+	ShortCode string `json:"ShortCode"`
+	// StartDate and EndDate use DateLayout; see FormatDate
+	StartDate string `json:"StartDate"`
+	EndDate   string `json:"EndDate"`
+	// OffSetValue is the number of transactions to skip
+	OffSetValue string `json:"OffSetValue"`
+}
+
+// QueryResponse represents a page of transactions
+type QueryResponse struct {
+	ResponseRefID   string        `json:"ResponseRefID"`
+	ResponseCode    string        `json:"ResponseCode"`
+	ResponseMessage string        `json:"ResponseMessage"`
+	Transactions    []Transaction `json:"-"`
+}
+
+// Transaction represents a transaction received by the shortcode
+type Transaction struct {
+	TransactionID    string  `json:"transactionId"`
+	TrxDate          string  `json:"trxDate"`
+	MSISDN           string  `json:"msisdn"`
+	Sender           string  `json:"sender"`
+	TransactionType  string  `json:"transactiontype"`
+	BillReference    string  `json:"billreference"`
+	Amount           float64 `json:"amount"`
+	OrganizationName string  `json:"organizationname"`
+}
+
+// FormatDate formats t for StartDate and EndDate
+func FormatDate(t time.Time) string {
+	return t.Format(DateLayout)
+}
+
+// Date parses TrxDate, which M-PESA reports in RFC 3339 or DateLayout
+func (t *Transaction) Date() (time.Time, error) {
+	if date, err := time.Parse(time.RFC3339, t.TrxDate); err == nil {
+		return date, nil
+	}
+	date, err := time.Parse(DateLayout, t.TrxDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid transaction date %q: %w", t.TrxDate, err)
+	}
+	return date, nil
+}
+
+// Register registers a shortcode for the Pull API
+func (s *PullTransactionsService) Register(req *RegisterRequest) (*RegisterResponse, error) {
+	return s.RegisterContext(context.Background(), req)
+}
+
+// RegisterContext is like Register but uses ctx for cancellation and deadlines
+func (s *PullTransactionsService) RegisterContext(ctx context.Context, req *RegisterRequest) (*RegisterResponse, error) {
+	if req.RequestType == "" {
+		req.RequestType = "Pull"
+	}
+
+	endpoint := "/pulltransactions/v1/register"
+	resp, err := transport.Do(telemetry.WithOperation(ctx, "pulltransactions.register"), s.client, "POST", endpoint, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register for pull transactions: %w", err)
+	}
+
+	var regResp RegisterResponse
+	if err := json.Unmarshal(resp, &regResp); err != nil {
+		return nil, fmt.Errorf("failed to parse pull transactions registration response: %w", err)
+	}
+
+	return &regResp, nil
+}
+
+// Query returns a page of transactions starting at req.OffSetValue
+func (s *PullTransactionsService) Query(req *QueryRequest) (*QueryResponse, error) {
+	return s.QueryContext(context.Background(), req)
+}
+
+// QueryContext is like Query but uses ctx for cancellation and deadlines
+func (s *PullTransactionsService) QueryContext(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	if req.OffSetValue == "" {
+		req.OffSetValue = "0"
+	}
+
+	endpoint := "/pulltransactions/v1/query"
+	resp, err := transport.Do(telemetry.WithOperation(ctx, "pulltransactions.query"), s.client, "POST", endpoint, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pull transactions: %w", err)
+	}
+
+	var queryResp QueryResponse
+	if err := json.Unmarshal(resp, &queryResp); err != nil {
+		return nil, fmt.Errorf("failed to parse pull transactions response: %w", err)
+	}
+
+	return &queryResp, nil
+}
+
+// QueryAll returns every transaction in the window of req, following OffSetValue
+// from page to page until a page is empty or brings no new transaction IDs.
+// A page whose ResponseCode is not SuccessCode is an error, and so is needing
+// more than MaxPages pages; in both cases the transactions gathered so far are
+// returned with the error.
+func (s *PullTransactionsService) QueryAll(req *QueryRequest) ([]Transaction, error) {
+	return s.QueryAllContext(context.Background(), req)
+}
+
+// QueryAllContext is like QueryAll but uses ctx for cancellation and deadlines
+func (s *PullTransactionsService) QueryAllContext(ctx context.Context, req *QueryRequest) ([]Transaction, error) {
+	offset := 0
+	if req.OffSetValue != "" {
+		var err error
+		if offset, err = strconv.Atoi(req.OffSetValue); err != nil {
+			return nil, fmt.Errorf("invalid OffSetValue %q: %w", req.OffSetValue, err)
+		}
+	}
+
+	var transactions []Transaction
+	seen := make(map[string]bool)
+	for pages := 0; pages < MaxPages; pages++ {
+		page := *req
+		page.OffSetValue = strconv.Itoa(offset)
+
+		resp, err := s.QueryContext(ctx, &page)
+		if err != nil {
+			return transactions, err
+		}
+		if resp.ResponseCode != SuccessCode {
+			return transactions, fmt.Errorf("pull transactions query at offset %d failed: %s %s",
+				offset, resp.ResponseCode, resp.ResponseMessage)
+		}
+
+		// Stop when the API repeats transactions it already returned
+		added := 0
+		for _, trx := range resp.Transactions {
+			if seen[trx.TransactionID] {
+				continue
+			}
+			seen[trx.TransactionID] = true
+			transactions = append(transactions, trx)
+			added++
+		}
+		if added == 0 {
+			return transactions, nil
+		}
+
+		offset += len(resp.Transactions)
+	}
+	return transactions, fmt.Errorf("pull transactions query did not finish within %d pages", MaxPages)
+}
+
+// UnmarshalJSON reads the transactions from Response, which M-PESA sends
+// as a list of lists, and accepts a flat list too
+func (r *QueryResponse) UnmarshalJSON(data []byte) error {
+	type queryResponse QueryResponse
+	var raw struct {
+		queryResponse
+		Response json.RawMessage `json:"Response"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = QueryResponse(raw.queryResponse)
+
+	response := bytes.TrimSpace(raw.Response)
+	if len(response) == 0 || string(response) == "null" {
+		return nil
+	}
+
+	var pages [][]Transaction
+	if err := json.Unmarshal(response, &pages); err == nil {
+		for _, page := range pages {
+			r.Transactions = append(r.Transactions, page...)
+		}
+		return nil
+	}
+	return json.Unmarshal(response, &r.Transactions)
+}
+
+// UnmarshalJSON accepts amounts given as numbers or strings
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	type transaction Transaction
+	var raw struct {
+		transaction
+		Amount json.RawMessage `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*t = Transaction(raw.transaction)
+
+	amount, err := models.StringOrNumber(raw.Amount)
+	if err != nil {
+		return fmt.Errorf("invalid amount for transaction %s: %w", t.TransactionID, err)
+	}
+	if amount == "" {
+		return nil
+	}
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount for transaction %s: %w", t.TransactionID, err)
+	}
+	t.Amount = value
+	return nil
+}
@@ -0,0 +1,247 @@
+package pulltransactions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClient struct {
+	doRequestFunc func(method, endpoint string, body interface{}) ([]byte, error)
+}
+
+func (m *mockClient) DoRequest(method, endpoint string, body interface{}) ([]byte, error) {
+	return m.doRequestFunc(method, endpoint, body)
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockResponse   []byte
+		mockError      error
+		expectedResult *RegisterResponse
+		expectedError  error
+	}{
+		{
+			name: "successful registration",
+			mockResponse: json.RawMessage(`{
+				"ResponseRefID": "18633-7271215-1",
+				"ResponseStatus": "1001",
+				"ShortCode": "600000",
+				"ResponseDescription": "ShortCode already registered"
+			}`),
+			expectedResult: &RegisterResponse{
+				ResponseRefID:       "18633-7271215-1",
+				ResponseStatus:      "1001",
+				ShortCode:           "600000",
+				ResponseDescription: "ShortCode already registered",
+			},
+		},
+		{
+			name:          "failed registration",
+			mockError:     errors.New("connection refused"),
+			expectedError: errors.New("failed to register for pull transactions: connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockClient{
+				doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+					assert.Equal(t, "/pulltransactions/v1/register", endpoint)
+					assert.Equal(t, "Pull", body.(*RegisterRequest).RequestType)
+					return tt.mockResponse, tt.mockError
+				},
+			}
+
+			service := NewPullTransactionsService(mockClient)
+			result, err := service.Register(&RegisterRequest{
+				ShortCode:       "600000",
+				NominatedNumber: "251700404789",
+				CallBackURL:     "https://example.com/pull",
+			})
+
+			if tt.expectedError != nil {
+				assert.EqualError(t, err, tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	mockClient := &mockClient{
+		doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+			assert.Equal(t, "/pulltransactions/v1/query", endpoint)
+			assert.Equal(t, "0", body.(*QueryRequest).OffSetValue)
+			return json.RawMessage(`{
+				"ResponseRefID": "26178-42530161-2",
+				"ResponseCode": "1000",
+				"ResponseMessage": "Success",
+				"Response": [[
+					{
+						"transactionId": "OHR6IAXXXX",
+						"trxDate": "2020-08-27T11:20:01Z",
+						"msisdn": "251700404789",
+						"sender": "MPESA",
+						"transactiontype": "c2b-pay-bill-debit",
+						"billreference": "INV-001",
+						"amount": "12.5",
+						"organizationname": "Daraja Pull API Test"
+					}
+				]]
+			}`), nil
+		},
+	}
+
+	service := NewPullTransactionsService(mockClient)
+	resp, err := service.Query(&QueryRequest{
+		ShortCode: "600000",
+		StartDate: FormatDate(time.Date(2020, 8, 27, 0, 0, 0, 0, time.UTC)),
+		EndDate:   "2020-08-28 00:00:00",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "1000", resp.ResponseCode)
+	require.Len(t, resp.Transactions, 1)
+	trx := resp.Transactions[0]
+	assert.Equal(t, "OHR6IAXXXX", trx.TransactionID)
+	assert.Equal(t, "251700404789", trx.MSISDN)
+	assert.Equal(t, "INV-001", trx.BillReference)
+	assert.Equal(t, 12.5, trx.Amount)
+
+	date, err := trx.Date()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 8, 27, 11, 20, 1, 0, time.UTC), date)
+}
+
+func TestTransactionAmount(t *testing.T) {
+	tests := []struct {
+		amount        string
+		expected      float64
+		expectedError string
+	}{
+		{`12.5`, 12.5, ""},
+		{`"12.5"`, 12.5, ""},
+		{`null`, 0, ""},
+		{`true`, 0, "invalid amount for transaction A: expected a string or number, got true"},
+		{`"ten"`, 0, `invalid amount for transaction A: strconv.ParseFloat: parsing "ten": invalid syntax`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			var trx Transaction
+			err := json.Unmarshal([]byte(`{"transactionId":"A","amount":`+tt.amount+`}`), &trx)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, trx.Amount)
+		})
+	}
+}
+
+func TestQueryAll(t *testing.T) {
+	var offsets []string
+	mockClient := &mockClient{
+		doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+			offset := body.(*QueryRequest).OffSetValue
+			offsets = append(offsets, offset)
+			switch offset {
+			case "0":
+				return []byte(`{"ResponseCode":"1000","Response":[[{"transactionId":"A","amount":1},{"transactionId":"B","amount":2}]]}`), nil
+			case "2":
+				return []byte(`{"ResponseCode":"1000","Response":[{"transactionId":"C","amount":3}]}`), nil
+			default:
+				return []byte(`{"ResponseCode":"1000","Response":[]}`), nil
+			}
+		},
+	}
+
+	service := NewPullTransactionsService(mockClient)
+	transactions, err := service.QueryAll(&QueryRequest{ShortCode: "600000"})
+	require.NoError(t, err)
+
+	var ids []string
+	for _, trx := range transactions {
+		ids = append(ids, trx.TransactionID)
+	}
+	assert.Equal(t, []string{"A", "B", "C"}, ids)
+	assert.Equal(t, []string{"0", "2", "3"}, offsets)
+}
+
+func TestQueryAllError(t *testing.T) {
+	calls := 0
+	mockClient := &mockClient{
+		doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+			calls++
+			if calls > 1 {
+				return nil, fmt.Errorf("system busy")
+			}
+			return []byte(`{"ResponseCode":"1000","Response":[[{"transactionId":"A","amount":1}]]}`), nil
+		},
+	}
+
+	service := NewPullTransactionsService(mockClient)
+	transactions, err := service.QueryAll(&QueryRequest{ShortCode: "600000"})
+	assert.EqualError(t, err, "failed to query pull transactions: system busy")
+	assert.Len(t, transactions, 1)
+}
+
+func TestQueryAllResponseCode(t *testing.T) {
+	mockClient := &mockClient{
+		doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+			if body.(*QueryRequest).OffSetValue == "0" {
+				return []byte(`{"ResponseCode":"1000","Response":[[{"transactionId":"A","amount":1}]]}`), nil
+			}
+			return []byte(`{"ResponseCode":"1001","ResponseMessage":"Invalid date range","Response":[]}`), nil
+		},
+	}
+
+	service := NewPullTransactionsService(mockClient)
+	transactions, err := service.QueryAll(&QueryRequest{ShortCode: "600000"})
+	assert.EqualError(t, err, "pull transactions query at offset 1 failed: 1001 Invalid date range")
+	assert.Len(t, transactions, 1)
+}
+
+func TestQueryAllStopsOnRepeatedPage(t *testing.T) {
+	calls := 0
+	mockClient := &mockClient{
+		doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+			calls++
+			// The API ignores the offset and keeps returning the same page
+			return []byte(`{"ResponseCode":"1000","Response":[[{"transactionId":"A","amount":1},{"transactionId":"B","amount":2}]]}`), nil
+		},
+	}
+
+	service := NewPullTransactionsService(mockClient)
+	transactions, err := service.QueryAll(&QueryRequest{ShortCode: "600000"})
+	require.NoError(t, err)
+	assert.Len(t, transactions, 2)
+	assert.Equal(t, 2, calls)
+}
+
+func TestQueryAllMaxPages(t *testing.T) {
+	calls := 0
+	mockClient := &mockClient{
+		doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+			calls++
+			return []byte(fmt.Sprintf(`{"ResponseCode":"1000","Response":[{"transactionId":"T%d","amount":1}]}`, calls)), nil
+		},
+	}
+
+	service := NewPullTransactionsService(mockClient)
+	transactions, err := service.QueryAll(&QueryRequest{ShortCode: "600000"})
+	assert.EqualError(t, err, fmt.Sprintf("pull transactions query did not finish within %d pages", MaxPages))
+	assert.Len(t, transactions, MaxPages)
+	assert.Equal(t, MaxPages, calls)
+}
@@ -0,0 +1,102 @@
+package qrcode
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct {
+	doRequestFunc func(method, endpoint string, body interface{}) ([]byte, error)
+}
+
+func (m *mockClient) DoRequest(method, endpoint string, body interface{}) ([]byte, error) {
+	return m.doRequestFunc(method, endpoint, body)
+}
+
+func TestGenerateQRCode(t *testing.T) {
+	image := base64.StdEncoding.EncodeToString(append(pngSignature, "data"...))
+
+	tests := []struct {
+		name           string
+		request        *QRRequest
+		mockResponse   []byte
+		mockError      error
+		expectedResult *QRResponse
+		expectedError  error
+	}{
+		{
+			name: "successful generation",
+			request: &QRRequest{
+				MerchantName: "Test Supermarket",
+				RefNo:        "Invoice Test",
+				Amount:       "1",
+				TrxCode:      BuyGoods,
+				CPI:          "373132",
+			},
+			mockResponse: json.RawMessage(`{
+				"ResponseCode": "AG_20191219_000043fdf61864fe9ff5",
+				"RequestID": "16738-27456357-1",
+				"ResponseDescription": "QR Code Successfully Generated.",
+				"QRCode": "` + image + `"
+			}`),
+			expectedResult: &QRResponse{
+				ResponseCode:        "AG_20191219_000043fdf61864fe9ff5",
+				RequestID:           "16738-27456357-1",
+				ResponseDescription: "QR Code Successfully Generated.",
+				QRCode:              image,
+			},
+		},
+		{
+			name:          "failed generation",
+			request:       &QRRequest{TrxCode: PayBill},
+			mockError:     errors.New("connection refused"),
+			expectedError: errors.New("failed to generate QR code: connection refused"),
+		},
+		{
+			name:          "invalid response",
+			request:       &QRRequest{TrxCode: PayBill},
+			mockResponse:  json.RawMessage(`invalid response`),
+			expectedError: errors.New("failed to parse QR code response: invalid character 'i' looking for beginning of value"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockClient{
+				doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+					assert.Equal(t, "/mpesa/qrcode/v1/generate", endpoint)
+					assert.Equal(t, "300", body.(*QRRequest).Size)
+					return tt.mockResponse, tt.mockError
+				},
+			}
+
+			service := NewQRCodeService(mockClient)
+			result, err := service.GenerateQRCode(tt.request)
+
+			if tt.expectedError != nil {
+				assert.EqualError(t, err, tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
+
+func TestDecodePNG(t *testing.T) {
+	data := append(pngSignature, "data"...)
+	decoded, err := DecodePNG(base64.StdEncoding.EncodeToString(data))
+	assert.NoError(t, err)
+	assert.Equal(t, data, decoded)
+
+	_, err = DecodePNG("not base64!")
+	assert.Error(t, err)
+
+	_, err = DecodePNG(base64.StdEncoding.EncodeToString([]byte("GIF89a")))
+	assert.EqualError(t, err, "failed to decode QR code: not a PNG image")
+}
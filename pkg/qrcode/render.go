@@ -0,0 +1,536 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+)
+
+// The local renderer encodes up to 213 bytes in byte mode at error
+// correction level M, which needs QR versions 1 to 10. That is plenty for
+// previews and keeps the tables below short.
+
+// ecBlocks describes the error correction layout of a QR version at level M
+type ecBlocks struct {
+	ecPerBlock int
+	// blocks lists the number of data codewords of each block
+	blocks []int
+}
+
+var levelM = [...]ecBlocks{
+	1:  {10, []int{16}},
+	2:  {16, []int{28}},
+	3:  {26, []int{44}},
+	4:  {18, []int{32, 32}},
+	5:  {24, []int{43, 43}},
+	6:  {16, []int{27, 27, 27, 27}},
+	7:  {18, []int{31, 31, 31, 31}},
+	8:  {22, []int{38, 38, 39, 39}},
+	9:  {22, []int{36, 36, 36, 37, 37}},
+	10: {26, []int{43, 43, 43, 43, 44}},
+}
+
+var alignmentPositions = [...][]int{
+	2:  {6, 18},
+	3:  {6, 22},
+	4:  {6, 26},
+	5:  {6, 30},
+	6:  {6, 34},
+	7:  {6, 22, 38},
+	8:  {6, 24, 42},
+	9:  {6, 26, 46},
+	10: {6, 28, 50},
+}
+
+// quietZone is the light border around the symbol, in modules
+const quietZone = 4
+
+// Preview renders req locally as a QRResponse so that code handling
+// generated QR codes can be exercised offline. The image encodes the
+// request fields rather than the payload M-PESA generates, so it is only a
+// preview and cannot be scanned to pay.
+func Preview(req *QRRequest) (*QRResponse, error) {
+	size := 300
+	if req.Size != "" {
+		var err error
+		if size, err = strconv.Atoi(req.Size); err != nil {
+			return nil, fmt.Errorf("invalid QR code size %q: %w", req.Size, err)
+		}
+	}
+
+	content := strings.Join([]string{string(req.TrxCode), req.CPI, req.Amount, req.RefNo, req.MerchantName}, "|")
+	img, err := Render(content, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QRResponse{
+		ResponseCode:        "00",
+		ResponseDescription: "QR code rendered locally",
+		QRCode:              base64.StdEncoding.EncodeToString(img),
+	}, nil
+}
+
+// Render encodes content as a QR code and returns it as a PNG image about
+// size pixels wide; the image is never smaller than one pixel per module
+func Render(content string, size int) ([]byte, error) {
+	modules, err := encode([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+
+	width := len(modules) + 2*quietZone
+	scale := size / width
+	if scale < 1 {
+		scale = 1
+	}
+
+	img := image.NewGray(image.Rect(0, 0, width*scale, width*scale))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	for y, row := range modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetGray((x+quietZone)*scale+dx, (y+quietZone)*scale+dy, color.Gray{})
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode QR code image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// symbol is a QR code being drawn; modules are indexed [y][x]
+type symbol struct {
+	version  int
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+// encode returns the modules of the smallest QR code holding data
+func encode(data []byte) ([][]bool, error) {
+	version := 0
+	for v := 1; v < len(levelM); v++ {
+		if 4+countBits(v)+8*len(data) <= 8*dataCodewords(v) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("content too long for QR code: %d bytes", len(data))
+	}
+
+	s := newSymbol(version)
+	s.drawFunctionPatterns()
+	s.drawCodewords(interleave(version, dataBits(version, data)))
+
+	// Keep the mask that makes the symbol easiest to read
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		s.applyMask(mask)
+		s.drawFormatBits(mask)
+		if penalty := s.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		s.applyMask(mask)
+	}
+	s.applyMask(best)
+	s.drawFormatBits(best)
+
+	return s.modules, nil
+}
+
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+func dataCodewords(version int) int {
+	total := 0
+	for _, n := range levelM[version].blocks {
+		total += n
+	}
+	return total
+}
+
+// dataBits builds the byte mode segment, terminated and padded to capacity
+func dataBits(version int, data []byte) []byte {
+	var bits bitBuffer
+	bits.append(0x4, 4)
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacity := 8 * dataCodewords(version)
+	terminator := capacity - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xec; len(bits) < capacity; pad ^= 0xec ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	return bits.bytes()
+}
+
+// interleave splits data into blocks, adds error correction and interleaves them
+func interleave(version int, data []byte) []byte {
+	layout := levelM[version]
+	divisor := rsDivisor(layout.ecPerBlock)
+
+	var blocks, ecc [][]byte
+	for _, n := range layout.blocks {
+		blocks = append(blocks, data[:n])
+		ecc = append(ecc, rsRemainder(data[:n], divisor))
+		data = data[n:]
+	}
+
+	var out []byte
+	longest := layout.blocks[len(layout.blocks)-1]
+	for i := 0; i < longest; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := 0; i < layout.ecPerBlock; i++ {
+		for _, block := range ecc {
+			out = append(out, block[i])
+		}
+	}
+	return out
+}
+
+func newSymbol(version int) *symbol {
+	size := 17 + 4*version
+	s := &symbol{version: version, size: size}
+	s.modules = make([][]bool, size)
+	s.function = make([][]bool, size)
+	for i := range s.modules {
+		s.modules[i] = make([]bool, size)
+		s.function[i] = make([]bool, size)
+	}
+	return s
+}
+
+func (s *symbol) set(x, y int, dark bool) {
+	s.modules[y][x] = dark
+	s.function[y][x] = true
+}
+
+func (s *symbol) drawFunctionPatterns() {
+	for i := 0; i < s.size; i++ {
+		s.set(6, i, i%2 == 0)
+		s.set(i, 6, i%2 == 0)
+	}
+
+	s.drawFinder(3, 3)
+	s.drawFinder(s.size-4, 3)
+	s.drawFinder(3, s.size-4)
+
+	positions := alignmentPositions[s.version]
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// Skip the corners taken by finder patterns
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			s.drawAlignment(x, y)
+		}
+	}
+
+	// Reserve the format areas; the bits are drawn once the mask is known
+	s.drawFormatBits(0)
+	s.drawVersionBits()
+}
+
+func (s *symbol) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || x >= s.size || y < 0 || y >= s.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			s.set(x, y, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (s *symbol) drawAlignment(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			s.set(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// formatBits returns the 15 bit format information for level M and mask
+func formatBits(mask int) int {
+	data := mask // level M is 00
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func (s *symbol) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool { return bits>>i&1 != 0 }
+
+	// Around the top left finder
+	for i := 0; i <= 5; i++ {
+		s.set(8, i, bit(i))
+	}
+	s.set(8, 7, bit(6))
+	s.set(8, 8, bit(7))
+	s.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		s.set(14-i, 8, bit(i))
+	}
+
+	// Split between the other two finders
+	for i := 0; i < 8; i++ {
+		s.set(s.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		s.set(8, s.size-15+i, bit(i))
+	}
+	s.set(8, s.size-8, true)
+}
+
+// versionBits returns the 18 bit version information
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1f25)
+	}
+	return version<<12 | rem
+}
+
+func (s *symbol) drawVersionBits() {
+	if s.version < 7 {
+		return
+	}
+	bits := versionBits(s.version)
+	for i := 0; i < 18; i++ {
+		dark := bits>>i&1 != 0
+		a, b := s.size-11+i%3, i/3
+		s.set(a, b, dark)
+		s.set(b, a, dark)
+	}
+}
+
+// drawCodewords places data in the zigzag order, skipping function modules
+func (s *symbol) drawCodewords(data []byte) {
+	i := 0
+	for right := s.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			// Skip the vertical timing pattern
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < s.size; vert++ {
+			y := vert
+			if upward {
+				y = s.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if s.function[y][x] || i >= len(data)*8 {
+					continue
+				}
+				s.modules[y][x] = data[i>>3]>>(7-i&7)&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// applyMask XORs the data modules with mask; applying it twice undoes it
+func (s *symbol) applyMask(mask int) {
+	for y := 0; y < s.size; y++ {
+		for x := 0; x < s.size; x++ {
+			if s.function[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			s.modules[y][x] = s.modules[y][x] != invert
+		}
+	}
+}
+
+// penalty scores the symbol by the four rules of the QR specification
+func (s *symbol) penalty() int {
+	at := func(x, y int, vertical bool) bool {
+		if vertical {
+			return s.modules[x][y]
+		}
+		return s.modules[y][x]
+	}
+
+	total := 0
+	for _, vertical := range []bool{false, true} {
+		for y := 0; y < s.size; y++ {
+			// Runs of five or more modules of the same color
+			run := 1
+			for x := 1; x < s.size; x++ {
+				if at(x, y, vertical) == at(x-1, y, vertical) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					total += run - 2
+				}
+				run = 1
+			}
+			if run >= 5 {
+				total += run - 2
+			}
+
+			// Patterns that look like finders
+			for x := 0; x+11 <= s.size; x++ {
+				var pattern int
+				for i := 0; i < 11; i++ {
+					pattern <<= 1
+					if at(x+i, y, vertical) {
+						pattern |= 1
+					}
+				}
+				if pattern == 0x5d0 || pattern == 0x05d {
+					total += 40
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := 0; y < s.size; y++ {
+		for x := 0; x < s.size; x++ {
+			if s.modules[y][x] {
+				dark++
+			}
+			// Blocks of 2x2 modules of the same color
+			if x > 0 && y > 0 {
+				c := s.modules[y][x]
+				if c == s.modules[y-1][x] && c == s.modules[y][x-1] && c == s.modules[y-1][x-1] {
+					total += 3
+				}
+			}
+		}
+	}
+
+	// Imbalance between dark and light modules
+	modules := s.size * s.size
+	k := (abs(dark*20-modules*10)+modules-1)/modules - 1
+	total += k * 10
+
+	return total
+}
+
+// bitBuffer accumulates bits most significant first
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 != 0)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return out
+}
+
+// rsDivisor returns the Reed-Solomon generator polynomial of the given degree
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// rsRemainder returns the error correction codewords of data
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= gfMultiply(d, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11d)
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
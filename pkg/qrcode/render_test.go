@@ -0,0 +1,157 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" at 1-M from the QR code specification walkthrough
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	ecc := rsRemainder(data, rsDivisor(10))
+	assert.Equal(t, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}, ecc)
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	assert.Equal(t, 0b101010000010010, formatBits(0))
+	assert.Equal(t, 0b100000011001110, formatBits(5))
+	assert.Equal(t, 0b100101010100000, formatBits(7))
+	assert.Equal(t, 0b000111110010010100, versionBits(7))
+	assert.Equal(t, 0b001010010011010011, versionBits(10))
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	for _, content := range []string{"BG|373132|1|Invoice Test|Test Supermarket", strings.Repeat("x", 200)} {
+		modules, err := encode([]byte(content))
+		require.NoError(t, err)
+
+		version := (len(modules) - 17) / 4
+		assert.Equal(t, content, string(decodeModules(t, version, modules)))
+	}
+
+	_, err := encode([]byte(strings.Repeat("x", 214)))
+	assert.Error(t, err)
+}
+
+func TestPreview(t *testing.T) {
+	resp, err := Preview(&QRRequest{
+		MerchantName: "Test Supermarket",
+		RefNo:        "Invoice Test",
+		Amount:       "1",
+		TrxCode:      BuyGoods,
+		CPI:          "373132",
+		Size:         "300",
+	})
+	require.NoError(t, err)
+
+	data, err := resp.PNG()
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, img.Bounds().Dx(), 300)
+	assert.Greater(t, img.Bounds().Dx(), 200)
+
+	_, err = Preview(&QRRequest{Size: "large"})
+	assert.Error(t, err)
+}
+
+// decodeModules reads the data bytes back out of an encoded symbol
+func decodeModules(t *testing.T, version int, modules [][]bool) []byte {
+	t.Helper()
+
+	s := newSymbol(version)
+	s.drawFunctionPatterns()
+
+	// Find the mask from the format bits next to the top left finder
+	var bits int
+	for i := 0; i <= 5; i++ {
+		bits |= b2i(modules[i][8]) << i
+	}
+	bits |= b2i(modules[7][8]) << 6
+	bits |= b2i(modules[8][8]) << 7
+	bits |= b2i(modules[8][7]) << 8
+	for i := 9; i < 15; i++ {
+		bits |= b2i(modules[8][14-i]) << i
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if formatBits(m) == bits {
+			mask = m
+		}
+	}
+	require.NotEqual(t, -1, mask, "format bits %015b", bits)
+
+	s.modules = modules
+	s.applyMask(mask)
+
+	var codewords bitBuffer
+	for right := s.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < s.size; vert++ {
+			y := vert
+			if upward {
+				y = s.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				if x := right - j; !s.function[y][x] {
+					codewords = append(codewords, s.modules[y][x])
+				}
+			}
+		}
+	}
+	raw := codewords.bytes()
+
+	// De-interleave the data codewords and check the error correction
+	layout := levelM[version]
+	blocks := make([][]byte, len(layout.blocks))
+	i := 0
+	for k := 0; k < layout.blocks[len(layout.blocks)-1]; k++ {
+		for b, n := range layout.blocks {
+			if k < n {
+				blocks[b] = append(blocks[b], raw[i])
+				i++
+			}
+		}
+	}
+	for k := 0; k < layout.ecPerBlock; k++ {
+		for b := range blocks {
+			assert.Equal(t, rsRemainder(blocks[b], rsDivisor(layout.ecPerBlock))[k], raw[i])
+			i++
+		}
+	}
+
+	data := bytes.Join(blocks, nil)
+	var stream bitBuffer
+	for _, b := range data {
+		stream.append(int(b), 8)
+	}
+	read := func(offset, n int) int {
+		v := 0
+		for _, bit := range stream[offset : offset+n] {
+			v = v<<1 | b2i(bit)
+		}
+		return v
+	}
+	require.Equal(t, 0x4, read(0, 4))
+	n := read(4, countBits(version))
+	out := make([]byte, n)
+	for k := range out {
+		out[k] = byte(read(4+countBits(version)+8*k, 8))
+	}
+	return out
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
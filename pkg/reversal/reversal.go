@@ -0,0 +1,129 @@
+package reversal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/internal/transport"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/security"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
+)
+
+type ReversalService struct {
+	client interface {
+		DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+	}
+}
+
+func NewReversalService(client interface {
+	DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+}) *ReversalService {
+	return &ReversalService{
+		client: client,
+	}
+}
+
+// ReversalRequest represents a transaction reversal request
+type ReversalRequest struct {
+	Initiator              string `json:"Initiator"`
+	SecurityCredential     string `json:"SecurityCredential"`
+	CommandID              string `json:"CommandID"`
+	TransactionID          string `json:"TransactionID"`
+	Amount                 string `json:"Amount"`
+	ReceiverParty          string `json:"ReceiverParty"`
+	RecieverIdentifierType string `json:"RecieverIdentifierType"`
+	ResultURL              string `json:"ResultURL"`
+	QueueTimeOutURL        string `json:"QueueTimeOutURL"`
+	Remarks                string `json:"Remarks"`
+	Occasion               string `json:"Occasion"`
+
+	// InitiatorPassword is encrypted into SecurityCredential when the latter is empty
+	InitiatorPassword string `json:"-"`
+}
+
+// ReversalResponse represents the synchronous acknowledgement of a reversal
+type ReversalResponse struct {
+	ConversationID           string `json:"ConversationID"`
+	OriginatorConversationID string `json:"OriginatorConversationID"`
+	ResponseCode             string `json:"ResponseCode"`
+	ResponseDescription      string `json:"ResponseDescription"`
+}
+
+// ReversalResult represents the asynchronous result of a reversal
+type ReversalResult struct {
+	models.Result
+
+	// OriginalTransactionID is the ID of the transaction that was reversed
+	OriginalTransactionID string
+	Amount                float64
+	Charge                float64
+	DebitAccountBalance   string
+	TransCompletedTime    string
+	CreditPartyPublicName string
+	DebitPartyPublicName  string
+}
+
+// Reverse reverses a completed transaction
+func (s *ReversalService) Reverse(req *ReversalRequest) (*ReversalResponse, error) {
+	return s.ReverseContext(context.Background(), req)
+}
+
+// ReverseContext is like Reverse but uses ctx for cancellation and deadlines
+func (s *ReversalService) ReverseContext(ctx context.Context, req *ReversalRequest) (*ReversalResponse, error) {
+	if req.TransactionID == "" {
+		return nil, fmt.Errorf("transaction ID is required")
+	}
+	if req.CommandID == "" {
+		req.CommandID = "TransactionReversal"
+	}
+	if req.RecieverIdentifierType == "" {
+		req.RecieverIdentifierType = "11"
+	}
+
+	credential, err := security.ResolveCredential(s.client, req.SecurityCredential, req.InitiatorPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate security credential: %w", err)
+	}
+	req.SecurityCredential = credential
+
+	endpoint := "/mpesa/reversal/v1/request"
+	resp, err := transport.Do(telemetry.WithOperation(ctx, "reversal.request"), s.client, "POST", endpoint, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reverse transaction: %w", err)
+	}
+
+	var reversalResp ReversalResponse
+	if err := json.Unmarshal(resp, &reversalResp); err != nil {
+		return nil, fmt.Errorf("failed to parse reversal response: %w", err)
+	}
+
+	return &reversalResp, nil
+}
+
+// ParseResult decodes the result M-PESA posts to the ResultURL of a reversal
+func ParseResult(r io.Reader) (*ReversalResult, error) {
+	result, err := models.ParseResult(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse reversal result: %w", err)
+	}
+
+	reversalResult := &ReversalResult{Result: *result}
+	params := result.ResultParameters
+
+	reversalResult.OriginalTransactionID, _ = params.Get("OriginalTransactionID")
+	reversalResult.DebitAccountBalance, _ = params.Get("DebitAccountBalance")
+	reversalResult.TransCompletedTime, _ = params.Get("TransCompletedTime")
+	reversalResult.CreditPartyPublicName, _ = params.Get("CreditPartyPublicName")
+	reversalResult.DebitPartyPublicName, _ = params.Get("DebitPartyPublicName")
+	if reversalResult.Amount, err = params.Float("Amount"); err != nil {
+		return nil, fmt.Errorf("failed to parse reversal result: %w", err)
+	}
+	if reversalResult.Charge, err = params.Float("Charge"); err != nil {
+		return nil, fmt.Errorf("failed to parse reversal result: %w", err)
+	}
+
+	return reversalResult, nil
+}
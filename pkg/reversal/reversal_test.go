@@ -0,0 +1,114 @@
+package reversal
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct {
+	doRequestFunc func(method, endpoint string, body interface{}) ([]byte, error)
+}
+
+func (m *mockClient) DoRequest(method, endpoint string, body interface{}) ([]byte, error) {
+	return m.doRequestFunc(method, endpoint, body)
+}
+
+func TestReverse(t *testing.T) {
+	tests := []struct {
+		name           string
+		request        *ReversalRequest
+		mockResponse   []byte
+		mockError      error
+		expectedResult *ReversalResponse
+		expectedError  error
+	}{
+		{
+			name:    "successful reversal",
+			request: &ReversalRequest{TransactionID: "NLJ41HAY6Q", Amount: "100", ReceiverParty: "101010"},
+			mockResponse: json.RawMessage(`{
+				"ConversationID": "AG_20240101_1234",
+				"OriginatorConversationID": "5678",
+				"ResponseCode": "0",
+				"ResponseDescription": "Accept the service request successfully."
+			}`),
+			expectedResult: &ReversalResponse{
+				ConversationID:           "AG_20240101_1234",
+				OriginatorConversationID: "5678",
+				ResponseCode:             "0",
+				ResponseDescription:      "Accept the service request successfully.",
+			},
+		},
+		{
+			name:          "missing transaction ID",
+			request:       &ReversalRequest{Amount: "100"},
+			expectedError: errors.New("transaction ID is required"),
+		},
+		{
+			name:          "failed reversal",
+			request:       &ReversalRequest{TransactionID: "NLJ41HAY6Q"},
+			mockError:     errors.New("connection refused"),
+			expectedError: errors.New("failed to reverse transaction: connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockClient{
+				doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+					req := body.(*ReversalRequest)
+					assert.Equal(t, "/mpesa/reversal/v1/request", endpoint)
+					assert.Equal(t, "TransactionReversal", req.CommandID)
+					assert.Equal(t, "11", req.RecieverIdentifierType)
+					return tt.mockResponse, tt.mockError
+				},
+			}
+
+			service := NewReversalService(mockClient)
+			result, err := service.Reverse(tt.request)
+
+			if tt.expectedError != nil {
+				assert.EqualError(t, err, tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
+
+func TestParseResult(t *testing.T) {
+	payload := `{
+		"Result": {
+			"ResultType": 0,
+			"ResultCode": 0,
+			"ResultDesc": "The service request is processed successfully.",
+			"OriginatorConversationID": "5678",
+			"ConversationID": "AG_20240101_1234",
+			"TransactionID": "NLJ0000000",
+			"ResultParameters": {
+				"ResultParameter": [
+					{"Key": "DebitAccountBalance", "Value": "Utility Account|ETB|51661.00|51661.00|0.00|0.00"},
+					{"Key": "Amount", "Value": 100},
+					{"Key": "TransCompletedTime", "Value": 20240101102115},
+					{"Key": "OriginalTransactionID", "Value": "NLJ41HAY6Q"},
+					{"Key": "Charge", "Value": 0},
+					{"Key": "CreditPartyPublicName", "Value": "251700404789 - John Doe"},
+					{"Key": "DebitPartyPublicName", "Value": "101010 - Test Shop"}
+				]
+			}
+		}
+	}`
+
+	result, err := ParseResult(strings.NewReader(payload))
+	assert.NoError(t, err)
+	assert.Equal(t, "NLJ41HAY6Q", result.OriginalTransactionID)
+	assert.Equal(t, "NLJ0000000", result.TransactionID)
+	assert.Equal(t, 100.0, result.Amount)
+	assert.Equal(t, "20240101102115", result.TransCompletedTime)
+	assert.Equal(t, "251700404789 - John Doe", result.CreditPartyPublicName)
+}
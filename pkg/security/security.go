@@ -0,0 +1,94 @@
+// Package security generates the SecurityCredential sent with initiator
+// requests by encrypting the initiator password with the M-PESA public
+// certificate.
+//
+// The SDK does not ship the sandbox or production certificates: download
+// the one for your environment from the M-PESA developer portal and pass it
+// with config.WithCertificate or config.WithCertificatePath. Callers that
+// already have an encrypted credential can set SecurityCredential directly
+// and need no certificate at all.
+package security
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/config"
+)
+
+// ParseCertificate parses a PEM or DER encoded X.509 certificate
+func ParseCertificate(data []byte) (*x509.Certificate, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+
+	cert, err := x509.ParseCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// LoadCertificate reads a PEM or DER encoded X.509 certificate from path
+func LoadCertificate(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	return ParseCertificate(data)
+}
+
+// CertificateFromConfig returns the certificate held in or referenced by cfg.
+// The caller must supply the certificate matching cfg.Environment; none is
+// built in.
+func CertificateFromConfig(cfg *config.Config) (*x509.Certificate, error) {
+	switch {
+	case len(cfg.Certificate) > 0:
+		return ParseCertificate(cfg.Certificate)
+	case cfg.CertificatePath != "":
+		return LoadCertificate(cfg.CertificatePath)
+	case cfg.Environment != "":
+		return nil, fmt.Errorf("no M-PESA certificate configured for %s: set Certificate or CertificatePath", cfg.Environment)
+	default:
+		return nil, fmt.Errorf("no M-PESA certificate configured")
+	}
+}
+
+// SecurityCredential encrypts the initiator password with the public key of
+// cert using PKCS#1 v1.5 and returns it base64 encoded
+func SecurityCredential(cert *x509.Certificate, initiatorPassword string) (string, error) {
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("certificate does not contain an RSA public key")
+	}
+
+	encrypted, err := rsa.EncryptPKCS1v15(rand.Reader, pub, []byte(initiatorPassword))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt initiator password: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+// ResolveCredential returns credential when it is already set, otherwise it
+// encrypts initiatorPassword with the certificate configured on client
+func ResolveCredential(client interface{}, credential, initiatorPassword string) (string, error) {
+	if credential != "" || initiatorPassword == "" {
+		return credential, nil
+	}
+
+	p, ok := client.(config.ConfigProvider)
+	if !ok || p.Config() == nil {
+		return "", fmt.Errorf("no M-PESA certificate configured")
+	}
+
+	cert, err := CertificateFromConfig(p.Config())
+	if err != nil {
+		return "", err
+	}
+	return SecurityCredential(cert, initiatorPassword)
+}
@@ -0,0 +1,103 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCertificate(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "apisandbox.safaricom.et"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return key, der
+}
+
+func decrypt(t *testing.T, key *rsa.PrivateKey, credential string) string {
+	t.Helper()
+
+	encrypted, err := base64.StdEncoding.DecodeString(credential)
+	require.NoError(t, err)
+	plain, err := rsa.DecryptPKCS1v15(rand.Reader, key, encrypted)
+	require.NoError(t, err)
+	return string(plain)
+}
+
+func TestSecurityCredential(t *testing.T) {
+	key, der := newTestCertificate(t)
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	for name, data := range map[string][]byte{"pem": pemData, "der": der} {
+		t.Run(name, func(t *testing.T) {
+			cert, err := ParseCertificate(data)
+			require.NoError(t, err)
+
+			credential, err := SecurityCredential(cert, "Safaricom999!*!")
+			assert.NoError(t, err)
+			assert.Equal(t, "Safaricom999!*!", decrypt(t, key, credential))
+		})
+	}
+
+	_, err := ParseCertificate([]byte("not a certificate"))
+	assert.Error(t, err)
+}
+
+type configClient struct {
+	config *config.Config
+}
+
+func (c *configClient) Config() *config.Config {
+	return c.config
+}
+
+func TestResolveCredential(t *testing.T) {
+	key, der := newTestCertificate(t)
+	path := filepath.Join(t.TempDir(), "cert.cer")
+	require.NoError(t, os.WriteFile(path, der, 0o600))
+
+	client := &configClient{config: &config.Config{CertificatePath: path}}
+
+	credential, err := ResolveCredential(client, "", "Safaricom999!*!")
+	assert.NoError(t, err)
+	assert.Equal(t, "Safaricom999!*!", decrypt(t, key, credential))
+
+	credential, err = ResolveCredential(client, "precomputed", "Safaricom999!*!")
+	assert.NoError(t, err)
+	assert.Equal(t, "precomputed", credential)
+
+	credential, err = ResolveCredential(client, "", "")
+	assert.NoError(t, err)
+	assert.Empty(t, credential)
+
+	_, err = ResolveCredential(&configClient{config: &config.Config{}}, "", "Safaricom999!*!")
+	assert.EqualError(t, err, "no M-PESA certificate configured")
+
+	_, err = ResolveCredential(&configClient{config: &config.Config{Environment: config.Production}}, "", "Safaricom999!*!")
+	assert.EqualError(t, err, "no M-PESA certificate configured for production: set Certificate or CertificatePath")
+
+	_, err = ResolveCredential(struct{}{}, "", "Safaricom999!*!")
+	assert.EqualError(t, err, "no M-PESA certificate configured")
+}
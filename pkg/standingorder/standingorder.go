@@ -0,0 +1,218 @@
+package standingorder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/internal/transport"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
+)
+
+// DateLayout is the layout of StartDate and EndDate
+const DateLayout = "20060102"
+
+// Frequency is how often a standing order charges the customer
+type Frequency string
+
+const (
+	OneOff     Frequency = "1"
+	Daily      Frequency = "2"
+	Weekly     Frequency = "3"
+	Monthly    Frequency = "4"
+	BiMonthly  Frequency = "5"
+	Quarterly  Frequency = "6"
+	HalfYearly Frequency = "7"
+	Yearly     Frequency = "8"
+)
+
+// Valid reports whether f is one of the frequencies M-PESA accepts
+func (f Frequency) Valid() bool {
+	return f >= OneOff && f <= Yearly && len(f) == 1
+}
+
+// TransactionType identifies whether a standing order pays a paybill or a till
+type TransactionType string
+
+const (
+	CustomerPayBill TransactionType = "Standing Order Customer Pay Bill"
+	// CustomerPayMerchant pays a till; the misspelling is what M-PESA expects
+	CustomerPayMerchant TransactionType = "Standing Order Customer Pay Marchant"
+)
+
+// Identifier types of the receiving party
+const (
+	IdentifierTill      = "2"
+	IdentifierShortCode = "4"
+)
+
+type StandingOrderService struct {
+	client interface {
+		DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+	}
+}
+
+func NewStandingOrderService(client interface {
+	DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+}) *StandingOrderService {
+	return &StandingOrderService{
+		client: client,
+	}
+}
+
+// StandingOrderRequest represents a request to create a standing order
+type StandingOrderRequest struct {
+	StandingOrderName string `json:"StandingOrderName"`
+	// StartDate and EndDate use DateLayout; see FormatDate
+	StartDate                   string          `json:"StartDate"`
+	EndDate                     string          `json:"EndDate"`
+	BusinessShortCode           string          `json:"BusinessShortCode"`
+	TransactionType             TransactionType `json:"TransactionType"`
+	ReceiverPartyIdentifierType string          `json:"ReceiverPartyIdentifierType"`
+	Amount                      string          `json:"Amount"`
+	// PartyA is the phone number of the customer being charged
+	PartyA           string    `json:"PartyA"`
+	CallBackURL      string    `json:"CallBackURL"`
+	AccountReference string    `json:"AccountReference"`
+	TransactionDesc  string    `json:"TransactionDesc"`
+	Frequency        Frequency `json:"Frequency"`
+}
+
+// StandingOrderResponse represents the acknowledgement of a standing order request
+type StandingOrderResponse struct {
+	ResponseHeader struct {
+		ResponseRefID       string `json:"responseRefID"`
+		ResponseCode        string `json:"responseCode"`
+		ResponseDescription string `json:"responseDescription"`
+		ResultDesc          string `json:"ResultDesc"`
+	} `json:"ResponseHeader"`
+	ResponseBody struct {
+		ResponseDescription string `json:"responseDescription"`
+		ResponseCode        string `json:"responseCode"`
+	} `json:"ResponseBody"`
+}
+
+// ResponseItem represents a single name/value entry in a callback
+type ResponseItem struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+// Callback represents the payload M-PESA posts to the CallBackURL once the
+// customer has accepted or declined the standing order
+type Callback struct {
+	ResponseHeader struct {
+		ResponseRefID       string `json:"responseRefID"`
+		ResponseCode        string `json:"responseCode"`
+		ResponseDescription string `json:"responseDescription"`
+		ResultDesc          string `json:"ResultDesc"`
+	} `json:"ResponseHeader"`
+	ResponseBody struct {
+		ResponseData []ResponseItem `json:"ResponseData"`
+	} `json:"ResponseBody"`
+
+	// TransactionID, Status and Msisdn are populated from ResponseData by ParseCallback
+	TransactionID string `json:"-"`
+	Status        string `json:"-"`
+	Msisdn        string `json:"-"`
+}
+
+// FormatDate formats t for StartDate and EndDate
+func FormatDate(t time.Time) string {
+	return t.Format(DateLayout)
+}
+
+// Accepted reports whether the request was accepted for processing
+func (r *StandingOrderResponse) Accepted() bool {
+	return r.ResponseHeader.ResponseCode == "200" || r.ResponseHeader.ResponseCode == "0"
+}
+
+// Successful reports whether the standing order was set up
+func (c *Callback) Successful() bool {
+	return c.ResponseHeader.ResponseCode == "0"
+}
+
+// Get returns the value of the response item with the given name
+func (c *Callback) Get(name string) (string, bool) {
+	for _, item := range c.ResponseBody.ResponseData {
+		if item.Name == name {
+			return item.Value, true
+		}
+	}
+	return "", false
+}
+
+// CreateStandingOrder asks a customer to approve recurring payments to a shortcode
+func (s *StandingOrderService) CreateStandingOrder(req *StandingOrderRequest) (*StandingOrderResponse, error) {
+	return s.CreateStandingOrderContext(context.Background(), req)
+}
+
+// CreateStandingOrderContext is like CreateStandingOrder but uses ctx for cancellation and deadlines
+func (s *StandingOrderService) CreateStandingOrderContext(ctx context.Context, req *StandingOrderRequest) (*StandingOrderResponse, error) {
+	if !req.Frequency.Valid() {
+		return nil, fmt.Errorf("invalid standing order frequency %q", req.Frequency)
+	}
+
+	if req.TransactionType == "" {
+		req.TransactionType = CustomerPayBill
+	}
+
+	if req.ReceiverPartyIdentifierType == "" {
+		req.ReceiverPartyIdentifierType = IdentifierShortCode
+		if req.TransactionType == CustomerPayMerchant {
+			req.ReceiverPartyIdentifierType = IdentifierTill
+		}
+	}
+
+	endpoint := "/standingorder/v1/createStandingOrderExternal"
+	resp, err := transport.Do(telemetry.WithOperation(ctx, "standingorder.create"), s.client, "POST", endpoint, req)
+	if err != nil {
+		return nil, fmt.Errorf("standing order request failed: %w", err)
+	}
+
+	var orderResp StandingOrderResponse
+	if err := json.Unmarshal(resp, &orderResp); err != nil {
+		return nil, fmt.Errorf("failed to parse standing order response: %w", err)
+	}
+
+	return &orderResp, nil
+}
+
+// ParseCallback decodes the payload M-PESA posts to the CallBackURL
+func ParseCallback(r io.Reader) (*Callback, error) {
+	var cb Callback
+	if err := json.NewDecoder(r).Decode(&cb); err != nil {
+		return nil, fmt.Errorf("failed to parse standing order callback: %w", err)
+	}
+	if cb.ResponseHeader.ResponseRefID == "" {
+		return nil, fmt.Errorf("failed to parse standing order callback: missing responseRefID")
+	}
+
+	cb.TransactionID, _ = cb.Get("TransactionID")
+	cb.Status, _ = cb.Get("Status")
+	cb.Msisdn, _ = cb.Get("Msisdn")
+
+	return &cb, nil
+}
+
+// UnmarshalJSON accepts both numeric and string item values
+func (i *ResponseItem) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Name  string          `json:"Name"`
+		Value json.RawMessage `json:"Value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	i.Name = raw.Name
+	value, err := models.StringOrNumber(raw.Value)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", raw.Name, err)
+	}
+	i.Value = value
+	return nil
+}
@@ -0,0 +1,158 @@
+package standingorder
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClient struct {
+	doRequestFunc func(method, endpoint string, body interface{}) ([]byte, error)
+}
+
+func (m *mockClient) DoRequest(method, endpoint string, body interface{}) ([]byte, error) {
+	return m.doRequestFunc(method, endpoint, body)
+}
+
+func TestCreateStandingOrder(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       *StandingOrderRequest
+		mockResponse  []byte
+		mockError     error
+		expectedType  string
+		expectedError error
+	}{
+		{
+			name: "paybill standing order",
+			request: &StandingOrderRequest{
+				StandingOrderName: "Monthly subscription",
+				StartDate:         FormatDate(time.Date(2024, 9, 5, 0, 0, 0, 0, time.UTC)),
+				EndDate:           "20250905",
+				BusinessShortCode: "174379",
+				Amount:            "4500",
+				PartyA:            "251700404789",
+				CallBackURL:       "https://example.com/ratiba",
+				AccountReference:  "ACC-001",
+				Frequency:         Monthly,
+			},
+			mockResponse: json.RawMessage(`{
+				"ResponseHeader": {
+					"responseRefID": "4dd9b5d9-d738-42ba-9326-2cc99e966000",
+					"responseCode": "200",
+					"responseDescription": "Request accepted for processing",
+					"ResultDesc": "The service request is processed successfully."
+				},
+				"ResponseBody": {
+					"responseDescription": "Request accepted for processing",
+					"responseCode": "200"
+				}
+			}`),
+			expectedType: IdentifierShortCode,
+		},
+		{
+			name:         "till standing order",
+			request:      &StandingOrderRequest{TransactionType: CustomerPayMerchant, Frequency: Weekly},
+			mockResponse: json.RawMessage(`{"ResponseHeader": {"responseRefID": "1", "responseCode": "200"}}`),
+			expectedType: IdentifierTill,
+		},
+		{
+			name:          "invalid frequency",
+			request:       &StandingOrderRequest{Frequency: "9"},
+			expectedError: errors.New(`invalid standing order frequency "9"`),
+		},
+		{
+			name:          "failed request",
+			request:       &StandingOrderRequest{Frequency: Daily},
+			mockError:     errors.New("connection refused"),
+			expectedError: errors.New("standing order request failed: connection refused"),
+		},
+		{
+			name:          "invalid response",
+			request:       &StandingOrderRequest{Frequency: Daily},
+			mockResponse:  json.RawMessage(`invalid response`),
+			expectedError: errors.New("failed to parse standing order response: invalid character 'i' looking for beginning of value"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockClient{
+				doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+					assert.Equal(t, "/standingorder/v1/createStandingOrderExternal", endpoint)
+					req := body.(*StandingOrderRequest)
+					assert.NotEmpty(t, req.TransactionType)
+					if tt.expectedType != "" {
+						assert.Equal(t, tt.expectedType, req.ReceiverPartyIdentifierType)
+					}
+					return tt.mockResponse, tt.mockError
+				},
+			}
+
+			service := NewStandingOrderService(mockClient)
+			result, err := service.CreateStandingOrder(tt.request)
+
+			if tt.expectedError != nil {
+				assert.EqualError(t, err, tt.expectedError.Error())
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, result.Accepted())
+		})
+	}
+}
+
+func TestStandingOrderRequestJSON(t *testing.T) {
+	body, err := json.Marshal(&StandingOrderRequest{
+		StartDate:       "20240905",
+		TransactionType: CustomerPayBill,
+		Frequency:       Monthly,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"Frequency":"4"`)
+	assert.Contains(t, string(body), `"TransactionType":"Standing Order Customer Pay Bill"`)
+	assert.Contains(t, string(body), `"StartDate":"20240905"`)
+}
+
+func TestParseCallback(t *testing.T) {
+	payload := `{
+		"ResponseHeader": {
+			"responseRefID": "0acfa2d6-2e6b-4e5f-a4a8-5b6cb0ca6b43",
+			"responseCode": "0",
+			"responseDescription": "The service request is processed successfully",
+			"ResultDesc": "The service request is processed successfully"
+		},
+		"ResponseBody": {
+			"ResponseData": [
+				{"Name": "TransactionID", "Value": "SC8F2IQMH5"},
+				{"Name": "responseCode", "Value": 0},
+				{"Name": "Status", "Value": "OKAY"},
+				{"Name": "Msisdn", "Value": "251******789"}
+			]
+		}
+	}`
+
+	cb, err := ParseCallback(strings.NewReader(payload))
+	require.NoError(t, err)
+	assert.True(t, cb.Successful())
+	assert.Equal(t, "SC8F2IQMH5", cb.TransactionID)
+	assert.Equal(t, "OKAY", cb.Status)
+	assert.Equal(t, "251******789", cb.Msisdn)
+
+	code, ok := cb.Get("responseCode")
+	assert.True(t, ok)
+	assert.Equal(t, "0", code)
+
+	_, err = ParseCallback(strings.NewReader(`{"ResponseHeader": {}}`))
+	assert.Error(t, err)
+
+	// Values must be strings or numbers
+	_, err = ParseCallback(strings.NewReader(`{"ResponseHeader": {"responseRefID": "1"}, "ResponseBody": {"ResponseData": [{"Name": "Status", "Value": {"code": 0}}]}}`))
+	assert.ErrorContains(t, err, `invalid value for Status: expected a string or number, got {"code": 0}`)
+}
@@ -0,0 +1,155 @@
+package stkpush
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+)
+
+// CallbackItem represents a single name/value entry in the callback metadata
+type CallbackItem struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value,omitempty"`
+}
+
+// CallbackMetadata holds the items M-PESA attaches to a successful payment
+type CallbackMetadata struct {
+	Item []CallbackItem `json:"Item"`
+}
+
+// STKCallback represents the asynchronous result of an STK push
+type STKCallback struct {
+	MerchantRequestID string            `json:"MerchantRequestID"`
+	CheckoutRequestID string            `json:"CheckoutRequestID"`
+	ResultCode        int               `json:"ResultCode"`
+	ResultDesc        string            `json:"ResultDesc"`
+	CallbackMetadata  *CallbackMetadata `json:"CallbackMetadata,omitempty"`
+
+	// Amount, MpesaReceiptNumber, TransactionDate and PhoneNumber are
+	// populated from CallbackMetadata by ParseCallback
+	Amount             float64 `json:"-"`
+	MpesaReceiptNumber string  `json:"-"`
+	TransactionDate    string  `json:"-"`
+	PhoneNumber        string  `json:"-"`
+}
+
+// CallbackRequest represents the payload M-PESA posts to the CallBackURL
+type CallbackRequest struct {
+	Body struct {
+		STKCallback STKCallback `json:"stkCallback"`
+	} `json:"Body"`
+}
+
+// CallbackResponse represents the acknowledgement returned to M-PESA
+type CallbackResponse struct {
+	ResultCode int    `json:"ResultCode"`
+	ResultDesc string `json:"ResultDesc"`
+}
+
+// Successful reports whether the payment was completed by the customer
+func (c *STKCallback) Successful() bool {
+	return c.ResultCode == 0
+}
+
+// Get returns the raw value of the metadata item with the given name
+func (m *CallbackMetadata) Get(name string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	for _, item := range m.Item {
+		if item.Name == name {
+			return item.Value, true
+		}
+	}
+	return "", false
+}
+
+// UnmarshalJSON accepts both numeric and string metadata values
+func (i *CallbackItem) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Name  string          `json:"Name"`
+		Value json.RawMessage `json:"Value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	i.Name = raw.Name
+	value, err := models.StringOrNumber(raw.Value)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", raw.Name, err)
+	}
+	i.Value = value
+	return nil
+}
+
+// ParseCallback decodes an STK push callback payload
+func ParseCallback(r io.Reader) (*STKCallback, error) {
+	var req CallbackRequest
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to parse STK push callback: %w", err)
+	}
+
+	cb := &req.Body.STKCallback
+	if cb.CheckoutRequestID == "" {
+		return nil, fmt.Errorf("failed to parse STK push callback: missing CheckoutRequestID")
+	}
+
+	if v, ok := cb.CallbackMetadata.Get("Amount"); ok {
+		amount, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse STK push callback amount: %w", err)
+		}
+		cb.Amount = amount
+	}
+	cb.MpesaReceiptNumber, _ = cb.CallbackMetadata.Get("MpesaReceiptNumber")
+	cb.TransactionDate, _ = cb.CallbackMetadata.Get("TransactionDate")
+	cb.PhoneNumber, _ = cb.CallbackMetadata.Get("PhoneNumber")
+
+	return cb, nil
+}
+
+// CallbackHandler is an http.Handler that receives STK push results
+type CallbackHandler struct {
+	handle func(ctx context.Context, cb *STKCallback) error
+}
+
+// NewCallbackHandler creates a handler that calls handle for every STK push result
+func NewCallbackHandler(handle func(ctx context.Context, cb *STKCallback) error) *CallbackHandler {
+	return &CallbackHandler{
+		handle: handle,
+	}
+}
+
+// ServeHTTP decodes the callback, dispatches it and acknowledges it to M-PESA
+func (h *CallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	cb, err := ParseCallback(r.Body)
+	if err != nil {
+		writeCallbackResponse(w, http.StatusBadRequest, CallbackResponse{ResultCode: 1, ResultDesc: "Rejected"})
+		return
+	}
+
+	if err := h.handle(r.Context(), cb); err != nil {
+		writeCallbackResponse(w, http.StatusInternalServerError, CallbackResponse{ResultCode: 1, ResultDesc: "Failed"})
+		return
+	}
+
+	writeCallbackResponse(w, http.StatusOK, CallbackResponse{ResultCode: 0, ResultDesc: "Accepted"})
+}
+
+func writeCallbackResponse(w http.ResponseWriter, status int, resp CallbackResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,109 @@
+package stkpush
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const successfulCallback = `{
+	"Body": {
+		"stkCallback": {
+			"MerchantRequestID": "29115-34620561-1",
+			"CheckoutRequestID": "ws_CO_191220191020363925",
+			"ResultCode": 0,
+			"ResultDesc": "The service request is processed successfully.",
+			"CallbackMetadata": {
+				"Item": [
+					{"Name": "Amount", "Value": 1.00},
+					{"Name": "MpesaReceiptNumber", "Value": "NLJ7RT61SV"},
+					{"Name": "Balance"},
+					{"Name": "TransactionDate", "Value": 20191219102115},
+					{"Name": "PhoneNumber", "Value": 251700404789}
+				]
+			}
+		}
+	}
+}`
+
+func TestParseCallback(t *testing.T) {
+	cb, err := ParseCallback(strings.NewReader(successfulCallback))
+	assert.NoError(t, err)
+	assert.True(t, cb.Successful())
+	assert.Equal(t, "29115-34620561-1", cb.MerchantRequestID)
+	assert.Equal(t, "ws_CO_191220191020363925", cb.CheckoutRequestID)
+	assert.Equal(t, 1.0, cb.Amount)
+	assert.Equal(t, "NLJ7RT61SV", cb.MpesaReceiptNumber)
+	assert.Equal(t, "20191219102115", cb.TransactionDate)
+	assert.Equal(t, "251700404789", cb.PhoneNumber)
+}
+
+func TestParseCallbackCancelled(t *testing.T) {
+	payload := `{"Body":{"stkCallback":{"MerchantRequestID":"1","CheckoutRequestID":"ws_CO_1","ResultCode":1032,"ResultDesc":"Request cancelled by user"}}}`
+
+	cb, err := ParseCallback(strings.NewReader(payload))
+	assert.NoError(t, err)
+	assert.False(t, cb.Successful())
+	assert.Equal(t, 1032, cb.ResultCode)
+	assert.Empty(t, cb.MpesaReceiptNumber)
+}
+
+func TestCallbackHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		payload        string
+		handleErr      error
+		expectedStatus int
+		expectedResult CallbackResponse
+	}{
+		{
+			name:           "accepted",
+			payload:        successfulCallback,
+			expectedStatus: http.StatusOK,
+			expectedResult: CallbackResponse{ResultCode: 0, ResultDesc: "Accepted"},
+		},
+		{
+			name:           "invalid payload",
+			payload:        `invalid`,
+			expectedStatus: http.StatusBadRequest,
+			expectedResult: CallbackResponse{ResultCode: 1, ResultDesc: "Rejected"},
+		},
+		{
+			name:           "handler error",
+			payload:        successfulCallback,
+			handleErr:      errors.New("database unavailable"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedResult: CallbackResponse{ResultCode: 1, ResultDesc: "Failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received *STKCallback
+			handler := NewCallbackHandler(func(ctx context.Context, cb *STKCallback) error {
+				received = cb
+				return tt.handleErr
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/callback", strings.NewReader(tt.payload))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			var resp CallbackResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expectedResult, resp)
+
+			if tt.expectedStatus != http.StatusBadRequest {
+				assert.Equal(t, "NLJ7RT61SV", received.MpesaReceiptNumber)
+			}
+		})
+	}
+}
@@ -1,11 +1,16 @@
 package stkpush
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/natnael-alemayehu/mpesa-sdk-go/internal/transport"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/config"
 	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
 )
 
 type STKPushService struct {
@@ -22,6 +27,23 @@ func NewSTKPushService(client interface {
 	}
 }
 
+// GeneratePassword derives the STK push password as base64(ShortCode+Passkey+Timestamp)
+func GeneratePassword(shortCode, passkey, timestamp string) string {
+	return base64.StdEncoding.EncodeToString([]byte(shortCode + passkey + timestamp))
+}
+
+// password derives a password for shortCode from the configured passkey
+func (s *STKPushService) password(shortCode, timestamp string) (string, error) {
+	var passkey string
+	if p, ok := s.client.(config.ConfigProvider); ok && p.Config() != nil {
+		passkey = p.Config().Passkeys[shortCode]
+	}
+	if passkey == "" {
+		return "", fmt.Errorf("no password given and no passkey configured for shortcode %q", shortCode)
+	}
+	return GeneratePassword(shortCode, passkey, timestamp), nil
+}
+
 type STKPushRequest struct {
 	MerchantRequestID string                 `json:"MerchantRequestID"`
 	BusinessShortCode string                 `json:"BusinessShortCode"`
@@ -47,16 +69,29 @@ type STKPushResponse struct {
 }
 
 func (s *STKPushService) InitiateSTKPush(req *STKPushRequest) (*STKPushResponse, error) {
+	return s.InitiateSTKPushContext(context.Background(), req)
+}
+
+// InitiateSTKPushContext is like InitiateSTKPush but uses ctx for cancellation and deadlines
+func (s *STKPushService) InitiateSTKPushContext(ctx context.Context, req *STKPushRequest) (*STKPushResponse, error) {
 	if req.Timestamp == "" {
 		req.Timestamp = time.Now().Format("20060102150405") // Format: YYYYMMDDHHMMSS
 	}
 
+	if req.Password == "" {
+		password, err := s.password(req.BusinessShortCode, req.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		req.Password = password
+	}
+
 	if req.TransactionType == "" {
 		req.TransactionType = "CustomerPayBillOnline"
 	}
 
 	endpoint := "/mpesa/stkpush/v3/processrequest"
-	resp, err := s.client.DoRequest("POST", endpoint, req)
+	resp, err := transport.Do(telemetry.WithOperation(ctx, "stkpush.initiate"), s.client, "POST", endpoint, req)
 	if err != nil {
 		return nil, fmt.Errorf("STK push request failed: %w", err)
 	}
@@ -68,3 +103,58 @@ func (s *STKPushService) InitiateSTKPush(req *STKPushRequest) (*STKPushResponse,
 
 	return &stkResp, nil
 }
+
+// STKQueryRequest represents a request for the status of an STK push
+type STKQueryRequest struct {
+	BusinessShortCode string `json:"BusinessShortCode"`
+	Password          string `json:"Password"`
+	Timestamp         string `json:"Timestamp"`
+	CheckoutRequestID string `json:"CheckoutRequestID"`
+}
+
+// STKQueryResponse represents the status of an STK push
+type STKQueryResponse struct {
+	ResponseCode        string `json:"ResponseCode"`
+	ResponseDescription string `json:"ResponseDescription"`
+	MerchantRequestID   string `json:"MerchantRequestID"`
+	CheckoutRequestID   string `json:"CheckoutRequestID"`
+	ResultCode          string `json:"ResultCode"`
+	ResultDesc          string `json:"ResultDesc"`
+}
+
+// QuerySTKPush checks the outcome of a previously initiated STK push
+func (s *STKPushService) QuerySTKPush(req *STKQueryRequest) (*STKQueryResponse, error) {
+	return s.QuerySTKPushContext(context.Background(), req)
+}
+
+// QuerySTKPushContext is like QuerySTKPush but uses ctx for cancellation and deadlines
+func (s *STKPushService) QuerySTKPushContext(ctx context.Context, req *STKQueryRequest) (*STKQueryResponse, error) {
+	if req.CheckoutRequestID == "" {
+		return nil, fmt.Errorf("checkout request ID is required")
+	}
+
+	if req.Timestamp == "" {
+		req.Timestamp = time.Now().Format("20060102150405") // Format: YYYYMMDDHHMMSS
+	}
+
+	if req.Password == "" {
+		password, err := s.password(req.BusinessShortCode, req.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		req.Password = password
+	}
+
+	endpoint := "/mpesa/stkpushquery/v1/query"
+	resp, err := transport.Do(telemetry.WithOperation(ctx, "stkpush.query"), s.client, "POST", endpoint, req)
+	if err != nil {
+		return nil, fmt.Errorf("STK push query failed: %w", err)
+	}
+
+	var queryResp STKQueryResponse
+	if err := json.Unmarshal(resp, &queryResp); err != nil {
+		return nil, fmt.Errorf("failed to parse STK push query response: %w", err)
+	}
+
+	return &queryResp, nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -45,3 +46,99 @@ func TestInitiateSTKPush(t *testing.T) {
 	assert.Equal(t, "Success", response.ResponseDescription)
 	assert.Equal(t, "Request accepted for processing", response.CustomerMessage)
 }
+
+type mockClient struct {
+	doRequestFunc func(method, endpoint string, body interface{}) ([]byte, error)
+}
+
+func (m *mockClient) DoRequest(method, endpoint string, body interface{}) ([]byte, error) {
+	return m.doRequestFunc(method, endpoint, body)
+}
+
+func TestQuerySTKPush(t *testing.T) {
+	var sent *STKQueryRequest
+	mockClient := &mockClient{
+		doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+			assert.Equal(t, "/mpesa/stkpushquery/v1/query", endpoint)
+			sent = body.(*STKQueryRequest)
+			return json.RawMessage(`{
+				"ResponseCode": "0",
+				"ResponseDescription": "The service request has been accepted successsfully",
+				"MerchantRequestID": "22205-34066-1",
+				"CheckoutRequestID": "ws_CO_13012021093521236557",
+				"ResultCode": "1032",
+				"ResultDesc": "Request cancelled by user"
+			}`), nil
+		},
+	}
+	service := NewSTKPushService(mockClient)
+
+	response, err := service.QuerySTKPush(&STKQueryRequest{
+		BusinessShortCode: "554433",
+		Password:          "123",
+		CheckoutRequestID: "ws_CO_13012021093521236557",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, sent.Timestamp, 14)
+	assert.Equal(t, "ws_CO_13012021093521236557", response.CheckoutRequestID)
+	assert.Equal(t, "1032", response.ResultCode)
+	assert.Equal(t, "Request cancelled by user", response.ResultDesc)
+
+	_, err = service.QuerySTKPush(&STKQueryRequest{BusinessShortCode: "554433"})
+	assert.EqualError(t, err, "checkout request ID is required")
+}
+
+type configMockClient struct {
+	mockClient
+	config *config.Config
+}
+
+func (m *configMockClient) Config() *config.Config {
+	return m.config
+}
+
+func TestInitiateSTKPushGeneratesPassword(t *testing.T) {
+	cfg, err := config.NewConfig("key", "secret", config.Sandbox, config.WithPasskey("554433", "passkey"))
+	assert.NoError(t, err)
+
+	var sent *STKPushRequest
+	mockClient := &configMockClient{
+		mockClient: mockClient{
+			doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+				sent = body.(*STKPushRequest)
+				return json.RawMessage(`{"ResponseCode": "0"}`), nil
+			},
+		},
+		config: cfg,
+	}
+	service := NewSTKPushService(mockClient)
+
+	_, err = service.InitiateSTKPush(&STKPushRequest{
+		BusinessShortCode: "554433",
+		Timestamp:         "20240101120000",
+		Amount:            "10.00",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "NTU0NDMzcGFzc2tleTIwMjQwMTAxMTIwMDAw", sent.Password)
+
+	_, err = service.InitiateSTKPush(&STKPushRequest{
+		BusinessShortCode: "554433",
+		Amount:            "10.00",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, GeneratePassword("554433", "passkey", sent.Timestamp), sent.Password)
+
+	sent = nil
+	_, err = service.InitiateSTKPush(&STKPushRequest{
+		BusinessShortCode: "112233",
+		Amount:            "10.00",
+	})
+	assert.EqualError(t, err, `no password given and no passkey configured for shortcode "112233"`)
+	assert.Nil(t, sent)
+
+	_, err = NewSTKPushService(&MockClient{}).QuerySTKPush(&STKQueryRequest{
+		BusinessShortCode: "554433",
+		CheckoutRequestID: "ws_CO_1",
+	})
+	assert.EqualError(t, err, `no password given and no passkey configured for shortcode "554433"`)
+}
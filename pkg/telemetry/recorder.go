@@ -0,0 +1,167 @@
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SpanData is a finished span captured by a Recorder
+type SpanData struct {
+	Name       string
+	Parent     string
+	Attributes map[string]interface{}
+	Errors     []error
+	Start      time.Time
+	End        time.Time
+}
+
+// Measurement is a counter increment or histogram value captured by a Recorder
+type Measurement struct {
+	Name       string
+	Value      float64
+	Attributes map[string]interface{}
+}
+
+// Recorder is an in-memory Tracer and Meter, intended for tests
+type Recorder struct {
+	mu         sync.Mutex
+	spans      []SpanData
+	counters   []Measurement
+	histograms []Measurement
+}
+
+// NewRecorder creates an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+type spanKey struct{}
+
+// Start implements Tracer
+func (r *Recorder) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	span := &recordedSpan{
+		recorder: r,
+		data: SpanData{
+			Name:       name,
+			Attributes: make(map[string]interface{}),
+			Start:      time.Now(),
+		},
+	}
+	if parent, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		span.data.Parent = parent.data.Name
+	}
+	span.SetAttributes(attrs...)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Add implements Meter
+func (r *Recorder) Add(ctx context.Context, name string, value int64, attrs ...Attribute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counters = append(r.counters, Measurement{Name: name, Value: float64(value), Attributes: attrMap(attrs)})
+}
+
+// Record implements Meter
+func (r *Recorder) Record(ctx context.Context, name string, value float64, attrs ...Attribute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.histograms = append(r.histograms, Measurement{Name: name, Value: value, Attributes: attrMap(attrs)})
+}
+
+// Spans returns the finished spans in the order they ended
+func (r *Recorder) Spans() []SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]SpanData(nil), r.spans...)
+}
+
+// Counter returns the sum of the increments to the counter name whose
+// attributes include attrs
+func (r *Recorder) Counter(name string, attrs ...Attribute) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var sum int64
+	for _, m := range r.counters {
+		if m.Name == name && matches(m.Attributes, attrs) {
+			sum += int64(m.Value)
+		}
+	}
+	return sum
+}
+
+// Histogram returns the values recorded in the histogram name whose
+// attributes include attrs
+func (r *Recorder) Histogram(name string, attrs ...Attribute) []float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var values []float64
+	for _, m := range r.histograms {
+		if m.Name == name && matches(m.Attributes, attrs) {
+			values = append(values, m.Value)
+		}
+	}
+	return values
+}
+
+// recordedSpan is the Span handed out by Recorder.Start
+type recordedSpan struct {
+	recorder *Recorder
+	mu       sync.Mutex
+	data     SpanData
+	ended    bool
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, attr := range attrs {
+		s.data.Attributes[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Errors = append(s.data.Errors, err)
+}
+
+func (s *recordedSpan) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	s.recorder.mu.Lock()
+	s.recorder.spans = append(s.recorder.spans, data)
+	s.recorder.mu.Unlock()
+}
+
+func attrMap(attrs []Attribute) map[string]interface{} {
+	m := make(map[string]interface{}, len(attrs))
+	for _, attr := range attrs {
+		m[attr.Key] = attr.Value
+	}
+	return m
+}
+
+func matches(have map[string]interface{}, want []Attribute) bool {
+	for _, attr := range want {
+		if have[attr.Key] != attr.Value {
+			return false
+		}
+	}
+	return true
+}
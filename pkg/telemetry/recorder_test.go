@@ -0,0 +1,44 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorderSpans(t *testing.T) {
+	r := NewRecorder()
+
+	ctx, parent := r.Start(context.Background(), "parent", String("a", "1"))
+	_, child := r.Start(ctx, "child")
+	child.RecordError(errors.New("failed"))
+	child.End()
+	parent.SetAttributes(Int("b", 2))
+	parent.End()
+	parent.End()
+
+	spans := r.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, "parent", spans[0].Parent)
+	assert.Len(t, spans[0].Errors, 1)
+	assert.Equal(t, "parent", spans[1].Name)
+	assert.Equal(t, map[string]interface{}{"a": "1", "b": 2}, spans[1].Attributes)
+}
+
+func TestRecorderMetrics(t *testing.T) {
+	r := NewRecorder()
+	ctx := context.Background()
+
+	r.Add(ctx, "requests", 1, String("op", "a"))
+	r.Add(ctx, "requests", 2, String("op", "b"))
+	r.Record(ctx, "latency", 0.5, String("op", "a"))
+
+	assert.Equal(t, int64(3), r.Counter("requests"))
+	assert.Equal(t, int64(2), r.Counter("requests", String("op", "b")))
+	assert.Equal(t, []float64{0.5}, r.Histogram("latency", String("op", "a")))
+	assert.Empty(t, r.Histogram("latency", String("op", "b")))
+}
@@ -0,0 +1,86 @@
+// Package telemetry defines the tracing and metrics hooks used by the client.
+//
+// The interfaces mirror the shape of the OpenTelemetry API without the SDK
+// depending on it; the separate otelmpesa module adapts OpenTelemetry tracers
+// and meters to them. Recorder is an in-memory implementation for tests.
+//
+// Services name the operation of each call with WithOperation; the client
+// uses that name for spans and metrics and falls back to the endpoint path.
+package telemetry
+
+import "context"
+
+// Attribute is a key/value pair attached to spans and measurements
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String creates a string attribute
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int creates an integer attribute
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+type operationKey struct{}
+
+// WithOperation returns a context that names the API operation of the calls
+// made with it, such as "b2b.payment"
+func WithOperation(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationKey{}, name)
+}
+
+// Operation returns the operation name set with WithOperation, or "" if there is none
+func Operation(ctx context.Context) string {
+	name, _ := ctx.Value(operationKey{}).(string)
+	return name
+}
+
+// Tracer starts spans
+type Tracer interface {
+	// Start creates a span named after the operation; the returned context
+	// carries it so that spans started from it become children
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a single traced operation
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Meter records counters and histograms
+type Meter interface {
+	// Add increments the counter name by value
+	Add(ctx context.Context, name string, value int64, attrs ...Attribute)
+	// Record adds value to the histogram name
+	Record(ctx context.Context, name string, value float64, attrs ...Attribute)
+}
+
+// NoopTracer is a Tracer that records nothing
+type NoopTracer struct{}
+
+// Start implements Tracer
+func (NoopTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+func (noopSpan) RecordError(err error)            {}
+func (noopSpan) End()                             {}
+
+// NoopMeter is a Meter that records nothing
+type NoopMeter struct{}
+
+// Add implements Meter
+func (NoopMeter) Add(ctx context.Context, name string, value int64, attrs ...Attribute) {}
+
+// Record implements Meter
+func (NoopMeter) Record(ctx context.Context, name string, value float64, attrs ...Attribute) {}
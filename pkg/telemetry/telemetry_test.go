@@ -0,0 +1,14 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperation(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", Operation(ctx))
+	assert.Equal(t, "b2b.payment", Operation(WithOperation(ctx, "b2b.payment")))
+}
@@ -0,0 +1,131 @@
+package transactionstatus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/natnael-alemayehu/mpesa-sdk-go/internal/transport"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/models"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/security"
+	"github.com/natnael-alemayehu/mpesa-sdk-go/pkg/telemetry"
+)
+
+type TransactionStatusService struct {
+	client interface {
+		DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+	}
+}
+
+func NewTransactionStatusService(client interface {
+	DoRequest(method, endpoint string, body interface{}) ([]byte, error)
+}) *TransactionStatusService {
+	return &TransactionStatusService{
+		client: client,
+	}
+}
+
+// QueryRequest represents a transaction status query
+type QueryRequest struct {
+	Initiator                string `json:"Initiator"`
+	SecurityCredential       string `json:"SecurityCredential"`
+	CommandID                string `json:"CommandID"`
+	TransactionID            string `json:"TransactionID,omitempty"`
+	OriginatorConversationID string `json:"OriginatorConversationID,omitempty"`
+	PartyA                   string `json:"PartyA"`
+	IdentifierType           string `json:"IdentifierType"`
+	ResultURL                string `json:"ResultURL"`
+	QueueTimeOutURL          string `json:"QueueTimeOutURL"`
+	Remarks                  string `json:"Remarks"`
+	Occasion                 string `json:"Occasion"`
+
+	// InitiatorPassword is encrypted into SecurityCredential when the latter is empty
+	InitiatorPassword string `json:"-"`
+}
+
+// QueryResponse represents the synchronous acknowledgement of a status query
+type QueryResponse struct {
+	ConversationID           string `json:"ConversationID"`
+	OriginatorConversationID string `json:"OriginatorConversationID"`
+	ResponseCode             string `json:"ResponseCode"`
+	ResponseDescription      string `json:"ResponseDescription"`
+}
+
+// QueryResult represents the asynchronous result of a status query
+type QueryResult struct {
+	models.Result
+
+	ReceiptNo         string
+	TransactionStatus string
+	Amount            float64
+	DebitPartyName    string
+	CreditPartyName   string
+	DebitAccountType  string
+	DebitPartyCharges string
+	TransactionReason string
+	ReasonType        string
+	InitiatedTime     string
+	FinalisedTime     string
+}
+
+// Query looks up a transaction by TransactionID or OriginatorConversationID
+func (s *TransactionStatusService) Query(req *QueryRequest) (*QueryResponse, error) {
+	return s.QueryContext(context.Background(), req)
+}
+
+// QueryContext is like Query but uses ctx for cancellation and deadlines
+func (s *TransactionStatusService) QueryContext(ctx context.Context, req *QueryRequest) (*QueryResponse, error) {
+	if req.TransactionID == "" && req.OriginatorConversationID == "" {
+		return nil, fmt.Errorf("transaction ID or originator conversation ID is required")
+	}
+	if req.CommandID == "" {
+		req.CommandID = "TransactionStatusQuery"
+	}
+
+	credential, err := security.ResolveCredential(s.client, req.SecurityCredential, req.InitiatorPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate security credential: %w", err)
+	}
+	req.SecurityCredential = credential
+
+	endpoint := "/mpesa/transactionstatus/v1/query"
+	resp, err := transport.Do(telemetry.WithOperation(ctx, "transactionstatus.query"), s.client, "POST", endpoint, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction status: %w", err)
+	}
+
+	var queryResp QueryResponse
+	if err := json.Unmarshal(resp, &queryResp); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction status response: %w", err)
+	}
+
+	return &queryResp, nil
+}
+
+// ParseResult decodes the result M-PESA posts to the ResultURL of a status query
+func ParseResult(r io.Reader) (*QueryResult, error) {
+	result, err := models.ParseResult(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transaction status result: %w", err)
+	}
+
+	queryResult := &QueryResult{Result: *result}
+	params := result.ResultParameters
+
+	queryResult.ReceiptNo, _ = params.Get("ReceiptNo")
+	queryResult.TransactionStatus, _ = params.Get("TransactionStatus")
+	queryResult.DebitPartyName, _ = params.Get("DebitPartyName")
+	queryResult.CreditPartyName, _ = params.Get("CreditPartyName")
+	queryResult.DebitAccountType, _ = params.Get("DebitAccountType")
+	queryResult.DebitPartyCharges, _ = params.Get("DebitPartyCharges")
+	queryResult.TransactionReason, _ = params.Get("TransactionReason")
+	queryResult.ReasonType, _ = params.Get("ReasonType")
+	queryResult.InitiatedTime, _ = params.Get("InitiatedTime")
+	queryResult.FinalisedTime, _ = params.Get("FinalisedTime")
+	if queryResult.Amount, err = params.Float("Amount"); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction status result: %w", err)
+	}
+
+	return queryResult, nil
+}
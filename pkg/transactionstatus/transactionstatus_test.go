@@ -0,0 +1,111 @@
+package transactionstatus
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct {
+	doRequestFunc func(method, endpoint string, body interface{}) ([]byte, error)
+}
+
+func (m *mockClient) DoRequest(method, endpoint string, body interface{}) ([]byte, error) {
+	return m.doRequestFunc(method, endpoint, body)
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name           string
+		request        *QueryRequest
+		mockResponse   []byte
+		mockError      error
+		expectedResult *QueryResponse
+		expectedError  error
+	}{
+		{
+			name:    "successful query",
+			request: &QueryRequest{TransactionID: "NLJ41HAY6Q", PartyA: "101010", IdentifierType: "4"},
+			mockResponse: json.RawMessage(`{
+				"ConversationID": "AG_20240101_1234",
+				"OriginatorConversationID": "5678",
+				"ResponseCode": "0",
+				"ResponseDescription": "Accept the service request successfully."
+			}`),
+			expectedResult: &QueryResponse{
+				ConversationID:           "AG_20240101_1234",
+				OriginatorConversationID: "5678",
+				ResponseCode:             "0",
+				ResponseDescription:      "Accept the service request successfully.",
+			},
+		},
+		{
+			name:          "missing identifiers",
+			request:       &QueryRequest{PartyA: "101010"},
+			expectedError: errors.New("transaction ID or originator conversation ID is required"),
+		},
+		{
+			name:          "failed query",
+			request:       &QueryRequest{OriginatorConversationID: "5678"},
+			mockError:     errors.New("connection refused"),
+			expectedError: errors.New("failed to query transaction status: connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockClient{
+				doRequestFunc: func(method, endpoint string, body interface{}) ([]byte, error) {
+					assert.Equal(t, "/mpesa/transactionstatus/v1/query", endpoint)
+					assert.Equal(t, "TransactionStatusQuery", body.(*QueryRequest).CommandID)
+					return tt.mockResponse, tt.mockError
+				},
+			}
+
+			service := NewTransactionStatusService(mockClient)
+			result, err := service.Query(tt.request)
+
+			if tt.expectedError != nil {
+				assert.EqualError(t, err, tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
+
+func TestParseResult(t *testing.T) {
+	payload := `{
+		"Result": {
+			"ResultType": 0,
+			"ResultCode": 0,
+			"ResultDesc": "The service request is processed successfully.",
+			"OriginatorConversationID": "5678",
+			"ConversationID": "AG_20240101_1234",
+			"TransactionID": "NLJ41HAY6R",
+			"ResultParameters": {
+				"ResultParameter": [
+					{"Key": "DebitPartyName", "Value": "251700404789 - John Doe"},
+					{"Key": "CreditPartyName", "Value": "101010 - Test Shop"},
+					{"Key": "ReceiptNo", "Value": "NLJ41HAY6Q"},
+					{"Key": "TransactionStatus", "Value": "Completed"},
+					{"Key": "Amount", "Value": 300},
+					{"Key": "FinalisedTime", "Value": 20240101102115}
+				]
+			}
+		}
+	}`
+
+	result, err := ParseResult(strings.NewReader(payload))
+	assert.NoError(t, err)
+	assert.Equal(t, "NLJ41HAY6Q", result.ReceiptNo)
+	assert.Equal(t, "Completed", result.TransactionStatus)
+	assert.Equal(t, 300.0, result.Amount)
+	assert.Equal(t, "251700404789 - John Doe", result.DebitPartyName)
+	assert.Equal(t, "20240101102115", result.FinalisedTime)
+}